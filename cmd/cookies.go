@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/cookies"
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// cookiesCmd represents the cookies command
+var cookiesCmd = &cobra.Command{
+	Use:   "cookies",
+	Short: "Manages the cookies of your collections",
+	Long: `Manages the cookie jars of your collections. The cookies set by the
+responses are stored in the .cookies.txt file of the collection of the request,
+and sent in the next requests of the collection, so sessions are kept between
+runs.
+
+The jars use the Netscape cookies.txt format, so they can be shared with curl
+(with -b and -c).`,
+}
+
+func init() {
+	rootCmd.AddCommand(cookiesCmd)
+}
+
+// loadCookieJar loads the cookie jar of the collection given as argument, or
+// of the collection chosen by the user.
+func loadCookieJar(args []string) (*cookies.Jar, string) {
+	collectionsPath := viper.GetString("collectionDirectory")
+
+	var collection string
+	if len(args) > 0 {
+		collection = args[0]
+	} else {
+		collection = prompts.Select("Which collection?", files.GetSubDirectories(collectionsPath))
+	}
+
+	collectionPath := filepath.Join(collectionsPath, collection)
+	if info, err := os.Stat(collectionPath); err != nil || !info.IsDir() {
+		cobra.CheckErr(fmt.Errorf("collection %q does not exist", collection))
+	}
+
+	jar, err := cookies.Load(filepath.Join(collectionPath, cookies.FileName))
+	cobra.CheckErr(err)
+	return jar, collection
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cookiesClearCmd represents the cookies clear command
+var cookiesClearCmd = &cobra.Command{
+	Use:   "clear [collection]",
+	Short: "Removes the cookies of a collection",
+	Long: `Removes every cookie of the cookie jar of a collection (e.g. to start a new
+session), or only the ones of a domain with --domain. You will be prompted to
+choose the collection if it's not provided.
+
+Example: httpmate cookies clear "collection name" --domain api.example.com`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		domain, err := cmd.Flags().GetString("domain")
+		cobra.CheckErr(err)
+
+		jar, collection := loadCookieJar(args)
+		cobra.CheckErr(jar.Clear(domain))
+
+		if domain != "" {
+			fmt.Printf("Cookies of %s were removed from collection %q\n", domain, collection)
+			return
+		}
+		fmt.Printf("Cookies were removed from collection %q\n", collection)
+	},
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesClearCmd)
+
+	cookiesClearCmd.Flags().String("domain", "", "Only removes the cookies of this domain")
+}
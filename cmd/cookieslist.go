@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// cookiesListCmd represents the cookies list command
+var cookiesListCmd = &cobra.Command{
+	Use:   "list [collection]",
+	Short: "Lists the cookies of a collection",
+	Long: `Lists the cookies stored in the cookie jar of a collection, which haven't
+expired. You will be prompted to choose the collection if it's not provided.
+
+Example: httpmate cookies list "collection name"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jar, collection := loadCookieJar(args)
+
+		all := jar.All()
+		if len(all) == 0 {
+			fmt.Printf("There are no cookies in collection %q\n", collection)
+			return
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "DOMAIN\tPATH\tNAME\tVALUE\tEXPIRES\tFLAGS")
+		for _, cookie := range all {
+			domain := cookie.Domain
+			if cookie.IncludeSubdomains {
+				domain = "." + domain
+			}
+
+			expires := "session"
+			if !cookie.Expires.IsZero() {
+				expires = cookie.Expires.Local().Format(time.DateTime)
+			}
+
+			flags := ""
+			if cookie.Secure {
+				flags += "secure "
+			}
+			if cookie.HTTPOnly {
+				flags += "httponly"
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", domain, cookie.Path, cookie.Name, cookie.Value, expires, flags)
+		}
+		cobra.CheckErr(writer.Flush())
+	},
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesListCmd)
+}
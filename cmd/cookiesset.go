@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/cookies"
+	"github.com/spf13/cobra"
+)
+
+// cookiesSetCmd represents the cookies set command
+var cookiesSetCmd = &cobra.Command{
+	Use:   "set <collection> <name=value>",
+	Short: "Adds a cookie to a collection",
+	Long: `Adds a cookie to the cookie jar of a collection, replacing the cookie with
+the same name, domain and path. The cookie is sent to the domain and its
+subdomains, unless --host-only is set. Without --expires, the cookie is kept
+until it's cleared.
+
+Example: httpmate cookies set "collection name" session=abc123 --domain api.example.com --expires 24h`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, value, found := strings.Cut(args[1], "=")
+		if !found || name == "" {
+			cobra.CheckErr(fmt.Errorf("the cookie must be like name=value, got %q", args[1]))
+		}
+
+		domain, err := cmd.Flags().GetString("domain")
+		cobra.CheckErr(err)
+		path, err := cmd.Flags().GetString("path")
+		cobra.CheckErr(err)
+		secure, err := cmd.Flags().GetBool("secure")
+		cobra.CheckErr(err)
+		httpOnly, err := cmd.Flags().GetBool("http-only")
+		cobra.CheckErr(err)
+		hostOnly, err := cmd.Flags().GetBool("host-only")
+		cobra.CheckErr(err)
+		expiresIn, err := cmd.Flags().GetDuration("expires")
+		cobra.CheckErr(err)
+
+		cookie := &cookies.Cookie{
+			Domain:            strings.TrimPrefix(strings.ToLower(domain), "."),
+			IncludeSubdomains: !hostOnly,
+			Path:              path,
+			Secure:            secure,
+			HTTPOnly:          httpOnly,
+			Name:              name,
+			Value:             value,
+		}
+		if expiresIn > 0 {
+			cookie.Expires = time.Now().Add(expiresIn)
+		}
+
+		jar, collection := loadCookieJar(args)
+		cobra.CheckErr(jar.Set(cookie))
+		fmt.Printf("Cookie %s was added to collection %q\n", name, collection)
+	},
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesSetCmd)
+
+	cookiesSetCmd.Flags().String("domain", "", "Domain to which the cookie is sent (required)")
+	cookiesSetCmd.Flags().String("path", "/", "Path to which the cookie is sent")
+	cookiesSetCmd.Flags().Bool("secure", false, "Only sends the cookie over HTTPS")
+	cookiesSetCmd.Flags().Bool("http-only", false, "Marks the cookie as HttpOnly")
+	cookiesSetCmd.Flags().Bool("host-only", false, "Only sends the cookie to the domain, not to its subdomains")
+	cookiesSetCmd.Flags().Duration("expires", 0, "Time after which the cookie expires (e.g. 24h)")
+	cookiesSetCmd.MarkFlagRequired("domain")
+}
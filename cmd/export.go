@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports your collections to other formats",
+	Long: `Exports your collections to other formats. Choose the format with one of
+the subcommands.`,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.PersistentFlags().StringP("output", "o", "", "File to which the export will be written (default is stdout)")
+}
+
+// loadCollectionRequests loads every request of a collection, including the
+// ones of its sub-collections. The Collection of each returned request is the
+// path of its sub-collection, relative to the collection.
+func loadCollectionRequests(collection string) []*configs.RequestConfig {
+	collectionsPath := viper.GetString("collectionDirectory")
+	collectionPath := filepath.Join(collectionsPath, collection)
+
+	if info, err := os.Stat(collectionPath); err != nil || !info.IsDir() {
+		cobra.CheckErr(fmt.Errorf("collection %q does not exist", collection))
+	}
+
+	requests := files.GetFilesFromDirectoryWithoutExtension(collectionPath)
+	if len(requests) == 0 {
+		cobra.CompError("There are no available requests in collection")
+		os.Exit(-1)
+	}
+
+	result := make([]*configs.RequestConfig, 0, len(requests))
+	for _, request := range requests {
+		reqConfig, err := configs.FindRequestConfig(collectionsPath, collection, request)
+		cobra.CheckErr(err)
+
+		// The folder of the request, relative to the collection.
+		folder := strings.TrimPrefix(filepath.Dir(request), string(filepath.Separator))
+		if folder == "." {
+			folder = ""
+		}
+		reqConfig.Collection = folder
+		result = append(result, reqConfig)
+	}
+	return result
+}
+
+// writeExport writes an export to the --output file, or to stdout.
+func writeExport(cmd *cobra.Command, data []byte) {
+	output, err := cmd.Flags().GetString("output")
+	cobra.CheckErr(err)
+
+	if output == "" {
+		fmt.Println(string(data))
+		return
+	}
+
+	err = os.WriteFile(output, data, 0644)
+	cobra.CheckErr(err)
+	fmt.Printf("Export was written to %s\n", output)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/httpfile"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exportHTTPCmd represents the export http command
+var exportHTTPCmd = &cobra.Command{
+	Use:   "http <collection>",
+	Short: "Exports a collection as a .http file",
+	Long: `Exports every request of a collection into a single .http file, as used by
+the JetBrains HTTP Client and the VS Code REST Client. The requests of the
+sub-collections are named after their sub-collection.
+
+The {{variables}} of the requests are kept as they are. If an environment is
+provided with --env, its variables are exported as file variables.
+
+The exported file can be stored in a collection, where its requests can be run
+as any other request.
+
+Example: httpmate export http "collection name" -o requests.http`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		collection := args[0]
+		reqConfigs := loadCollectionRequests(collection)
+
+		environment, err := cmd.Flags().GetString("env")
+		cobra.CheckErr(err)
+
+		collectionsPath := viper.GetString("collectionDirectory")
+		file := &httpfile.File{
+			Variables: variables.LoadEnvironment(collectionsPath, filepath.Join(collectionsPath, collection), environment),
+			Requests:  make([]httpfile.Request, 0, len(reqConfigs)),
+		}
+
+		warnings := make([]string, 0)
+		for _, reqConfig := range reqConfigs {
+			name := filepath.Join(reqConfig.Collection, reqConfig.RequestName)
+			name = strings.Join(strings.Fields(files.SafeFileName(name)), "-")
+			request, requestWarnings := reqConfig.ToHTTPFileRequest(name)
+			file.Requests = append(file.Requests, request)
+			for _, warning := range requestWarnings {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", name, warning))
+			}
+		}
+
+		writeExport(cmd, []byte(httpfile.Format(file)))
+		// The export can be written to stdout, so the warnings aren't.
+		printWarnings(os.Stderr, "Some details could not be exported:", warnings)
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportHTTPCmd)
+
+	exportHTTPCmd.Flags().StringP("env", "e", "", "Environment whose variables will be exported as file variables")
+}
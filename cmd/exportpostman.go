@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/postman"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exportPostmanCmd represents the export postman command
+var exportPostmanCmd = &cobra.Command{
+	Use:   "postman <collection>",
+	Short: "Exports a collection as a Postman collection",
+	Long: `Exports a collection as a Postman Collection (v2.1), which can be imported
+into Postman. Sub-collections become Postman folders.
+
+The {{variables}} of the requests are kept as they are. If an environment is
+provided with --env, its variables are exported as collection variables.
+
+Example: httpmate export postman "collection name" --env staging -o collection.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		collection := args[0]
+		reqConfigs := loadCollectionRequests(collection)
+
+		environment, err := cmd.Flags().GetString("env")
+		cobra.CheckErr(err)
+
+		collectionsPath := viper.GetString("collectionDirectory")
+		vars := variables.LoadEnvironment(collectionsPath, filepath.Join(collectionsPath, collection), environment)
+
+		postmanCollection := postman.Export(filepath.Base(collection), reqConfigs, vars)
+		data, err := json.MarshalIndent(postmanCollection, "", "\t")
+		cobra.CheckErr(err)
+
+		writeExport(cmd, data)
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportPostmanCmd)
+
+	exportPostmanCmd.Flags().StringP("env", "e", "", "Environment whose variables will be exported as collection variables")
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// flowCmd represents the flow command
+var flowCmd = &cobra.Command{
+	Use:   "flow",
+	Short: "Runs sequences of requests",
+	Long: `Runs workflows, which are sequences of requests of your collections that
+share the values extracted from their responses (e.g. create user, login,
+create order and fetch order).`,
+}
+
+func init() {
+	rootCmd.AddCommand(flowCmd)
+}
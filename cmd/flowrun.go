@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/extract"
+	"github.com/joaocgduarte/httpmate/internal/flow"
+	"github.com/joaocgduarte/httpmate/internal/responseprinter"
+	"github.com/joaocgduarte/httpmate/internal/timing"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// flowRunCmd represents the flow run command
+var flowRunCmd = &cobra.Command{
+	Use:   "run <file>",
+	Short: "Runs a workflow file",
+	Long: `Runs the steps of a workflow file in order, printing the response of each
+step, and stops at the first step that fails. The command exits with a
+non-zero code if any step fails.
+
+Workflow files are written in YAML (or JSON):
+
+    name: checkout
+    env: staging
+    variables:
+      email: john@example.com
+    steps:
+      - name: create user
+        request: users/create
+        expect_status: [201]
+      - name: login
+        request: users/login
+        extract:
+          - variable: token
+            json_path: $.token
+      - name: create order
+        request: orders/create
+        variables:
+          quantity: "2"
+        when:
+          step: login
+          status: [200]
+        extract:
+          - variable: order_id
+            header: X-Order-Id
+      - request: orders/get
+
+Each step performs a "collection/request", with its {{placeholders}} replaced
+by the variables of the environment, of the flow, the ones extracted by the
+previous steps and the ones of the step itself (in increasing order of
+precedence). Extracted values are also saved into the variable store, like
+with the "extract" section of the requests.
+
+A step fails if no response is received, if its status is not one of
+"expect_status" (any status below 400 by default), if any assertion of its
+request fails, or if a value can't be extracted. Steps with a "when"
+condition are skipped unless the given step (the previous one by default)
+got one of the given status codes.
+
+Example: httpmate flow run checkout.yaml --env local`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflow, err := flow.ParseFile(args[0])
+		cobra.CheckErr(err)
+
+		environment, err := cmd.Flags().GetString("env")
+		cobra.CheckErr(err)
+		waterfall, err := cmd.Flags().GetBool("waterfall")
+		cobra.CheckErr(err)
+		printOptions := responseprinter.Options{Waterfall: waterfall}
+		if environment == "" {
+			environment = workflow.Environment
+		}
+
+		collectionsPath := viper.GetString("collectionDirectory")
+
+		flowVariables := map[string]string{}
+		for key, value := range workflow.Variables {
+			flowVariables[key] = value
+		}
+
+		statusCodes := map[string]int{}
+		previousStatusCode := 0
+		performed, skipped := 0, 0
+		failed := false
+		for i, step := range workflow.Steps {
+			fmt.Printf("Step %d/%d: %s\n", i+1, len(workflow.Steps), step.Title())
+
+			if !step.When.Matches(statusCodes, previousStatusCode) {
+				fmt.Println("Skipped, since the condition was not met")
+				fmt.Println()
+				previousStatusCode = 0
+				skipped++
+				continue
+			}
+
+			reqConfig, err := configs.FindRequestConfig(collectionsPath, "", step.Request)
+			cobra.CheckErr(err)
+
+			vars := loadEnvironment(environment, reqConfig)
+			for key, value := range flowVariables {
+				vars[key] = value
+			}
+			// The variables of the step can reference the other variables.
+			stepVariables := map[string]string{}
+			for key, value := range step.Variables {
+				stepVariables[key] = variables.Interpolate(value, vars)
+			}
+			for key, value := range stepVariables {
+				vars[key] = value
+			}
+
+			statusCode, extracted, failures := runFlowStep(step, reqConfig.ResolveVariables(vars), printOptions)
+			performed++
+			for key, value := range extracted {
+				flowVariables[key] = value
+			}
+			if len(extracted) > 0 {
+				variables.SaveToStore(collectionsPath, extracted)
+			}
+
+			if len(failures) > 0 {
+				fmt.Printf("Step %d failed:\n", i+1)
+				for _, failure := range failures {
+					fmt.Printf("    %s\n", failure)
+				}
+				fmt.Println()
+				failed = true
+				break
+			}
+
+			if step.Name != "" {
+				statusCodes[step.Name] = statusCode
+			}
+			previousStatusCode = statusCode
+			fmt.Println()
+		}
+
+		// The flow stops at the first step which fails.
+		if failed {
+			fmt.Printf("Flow failed: %d steps performed, %d skipped\n", performed, skipped)
+			os.Exit(1)
+		}
+		fmt.Printf("Flow completed: %d steps performed, %d skipped\n", performed, skipped)
+	},
+}
+
+// runFlowStep performs the request of a step and prints its response. It
+// returns the status code, the extracted values and the reasons why the step
+// failed, if any.
+func runFlowStep(step flow.Step, reqConfig *configs.RequestConfig, printOptions responseprinter.Options) (int, map[string]string, []string) {
+	failures := make([]string, 0)
+
+	client := reqConfig.NewHTTPClient()
+	req, recorder := timing.Trace(reqConfig.BuildHTTPRequest())
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	processingTime := time.Since(startTime)
+	if err != nil {
+		return 0, nil, append(failures, err.Error())
+	}
+	defer resp.Body.Close()
+	recorder.WrapBody(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, append(failures, fmt.Sprintf("error reading response body: %s", err))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	responseprinter.PrintHTTPResponse(resp, recorder, printOptions)
+
+	if !step.Succeeded(resp.StatusCode) {
+		failures = append(failures, fmt.Sprintf("unexpected status %s", resp.Status))
+	}
+
+	for _, result := range reqConfig.Assertions.Evaluate(resp, body, processingTime) {
+		if !result.Passed {
+			failures = append(failures, result.Message)
+		}
+	}
+
+	extractions := append(append([]extract.Extraction{}, reqConfig.Extract...), step.Extract...)
+	extracted, errs := extract.Apply(extractions, resp, body)
+	for _, err := range errs {
+		failures = append(failures, fmt.Sprintf("could not extract %s", err))
+	}
+
+	return resp.StatusCode, extracted, failures
+}
+
+func init() {
+	flowCmd.AddCommand(flowRunCmd)
+
+	flowRunCmd.Flags().StringP("env", "e", "", "Environment whose variables will replace the {{placeholders}} of the requests")
+	flowRunCmd.Flags().Bool("waterfall", false, "Shows the timings of the requests as waterfall charts")
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// selectRequestConfig returns the request chosen through the --collection and
+// --request flags, or through a "collection/request" positional argument.
+// When no request is specified, the user is prompted to select one. The
+// returned boolean reports whether the user was prompted.
+func selectRequestConfig(cmd *cobra.Command, args []string, label string) (*configs.RequestConfig, bool) {
+	collectionDir := viper.GetString("collectionDirectory")
+
+	chosenCollection, err := cmd.Flags().GetString("collection")
+	cobra.CheckErr(err)
+
+	specifiedRequest, err := cmd.Flags().GetString("request")
+	cobra.CheckErr(err)
+
+	if specifiedRequest == "" && len(args) > 0 {
+		specifiedRequest = args[0]
+	}
+
+	if specifiedRequest != "" {
+		reqConfig, err := configs.FindRequestConfig(collectionDir, chosenCollection, specifiedRequest)
+		cobra.CheckErr(err)
+		return reqConfig, false
+	}
+
+	if chosenCollection != "" {
+		collectionDir = filepath.Join(collectionDir, chosenCollection)
+	}
+
+	return configs.PromptNewExistentRequestConfig(label, collectionDir), true
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Imports requests from other formats into your collections",
+	Long: `Imports requests from other formats into your collections. Choose the
+format with one of the subcommands.`,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.PersistentFlags().StringP("collection", "c", "", "Collection to which the requests will be imported")
+	importCmd.PersistentFlags().BoolP("force", "f", false, "If set, existing requests with the same name will be overwritten")
+}
+
+// importCollectionPath returns the path of the collection chosen with the
+// --collection flag. If it was not provided, the default collection is used,
+// and if there's none, the user is prompted to choose one.
+func importCollectionPath(cmd *cobra.Command, defaultCollection string) string {
+	collectionsPath := viper.GetString("collectionDirectory")
+
+	collection, err := cmd.Flags().GetString("collection")
+	cobra.CheckErr(err)
+
+	if collection == "" {
+		collection = defaultCollection
+	}
+	if collection == "" {
+		return configs.PromptCollection(collectionsPath)
+	}
+
+	collectionPath := filepath.Join(collectionsPath, collection)
+	files.CreateDirectory(collectionPath)
+	return collectionPath
+}
+
+// saveImportedRequests writes imported requests to their collections. Unless
+// --force is set, nothing is written if any of the requests already exists.
+func saveImportedRequests(cmd *cobra.Command, reqConfigs ...*configs.RequestConfig) {
+	force, err := cmd.Flags().GetBool("force")
+	cobra.CheckErr(err)
+
+	for _, reqConfig := range reqConfigs {
+		requestPath := filepath.Join(reqConfig.Collection, fmt.Sprintf("%s.json", reqConfig.RequestName))
+		if _, err := os.Stat(requestPath); err == nil && !force {
+			cobra.CheckErr(fmt.Errorf("request %q already exists, use --force to overwrite it", requestPath))
+		}
+	}
+
+	for _, reqConfig := range reqConfigs {
+		files.CreateDirectory(reqConfig.Collection)
+		reqConfig.WriteToJSONFile()
+	}
+}
+
+// printImportWarnings prints what could not be translated during an import.
+func printImportWarnings(warnings []string) {
+	printWarnings(os.Stdout, "Some details could not be imported:", warnings)
+}
+
+// printWarnings prints what could not be translated, under the title.
+func printWarnings(out io.Writer, title string, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, title)
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "    %s\n", warning)
+	}
+}
+
+// promptRequestName returns the --name flag, or prompts for it.
+func promptRequestName(cmd *cobra.Command) string {
+	name, err := cmd.Flags().GetString("name")
+	cobra.CheckErr(err)
+
+	if name == "" {
+		name = prompts.Prompt("Request name")
+	}
+	return name
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joaocgduarte/httpmate/internal/curl"
+	"github.com/spf13/cobra"
+)
+
+// importCurlCmd represents the import curl command
+var importCurlCmd = &cobra.Command{
+	Use:   "curl [command]",
+	Short: "Imports a request from a cURL command",
+	Long: `Imports a request from a cURL command, such as the ones copied from the
+browser devtools. The command is read from the argument, or from stdin if no
+argument is provided.
+
+Supported options are -X, -H, -d/--data/--data-raw/--data-binary/--data-urlencode,
+-F, -u, -b, -A, -e, -G, -I and --compressed. Any option that could not be
+translated is reported.
+
+Example: httpmate import curl "curl -X POST https://example.com -d 'a=b'" --collection example --name create
+Example: pbpaste | httpmate import curl --collection example --name create`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		command := ""
+		if len(args) > 0 && args[0] != "-" {
+			command = args[0]
+		} else {
+			// The prompts can't be used once stdin has been consumed.
+			for _, flag := range []string{"collection", "name"} {
+				if value, _ := cmd.Flags().GetString(flag); value == "" {
+					cobra.CheckErr(fmt.Errorf("--%s is required when reading the command from stdin", flag))
+				}
+			}
+
+			input, err := io.ReadAll(os.Stdin)
+			cobra.CheckErr(err)
+			command = string(input)
+		}
+
+		reqConfig, warnings, err := curl.Parse(command)
+		cobra.CheckErr(err)
+
+		reqConfig.Collection = importCollectionPath(cmd, "")
+		reqConfig.RequestName = promptRequestName(cmd)
+		saveImportedRequests(cmd, reqConfig)
+
+		printImportWarnings(warnings)
+		fmt.Println("Request was added to collection")
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importCurlCmd)
+
+	importCurlCmd.Flags().StringP("name", "n", "", "Name of the imported request")
+}
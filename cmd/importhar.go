@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joaocgduarte/httpmate/internal/har"
+	"github.com/spf13/cobra"
+)
+
+// importHARCmd represents the import har command
+var importHARCmd = &cobra.Command{
+	Use:   "har <file>",
+	Short: "Imports the requests of a HAR file",
+	Long: `Imports the requests of an HTTP Archive (HAR) file, such as the ones
+exported by the browser devtools or by proxies. A request is created for each
+entry of the archive, which can be filtered by domain (matching its 
+sub-domains too) and by method.
+
+Example: httpmate import har session.har --collection example --domain example.com --method POST`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		cobra.CheckErr(err)
+
+		domains, err := cmd.Flags().GetStringSlice("domain")
+		cobra.CheckErr(err)
+
+		methods, err := cmd.Flags().GetStringSlice("method")
+		cobra.CheckErr(err)
+
+		result, err := har.Import(data, har.Filter{Domains: domains, Methods: methods})
+		cobra.CheckErr(err)
+
+		if len(result.Requests) == 0 {
+			cobra.CheckErr(fmt.Errorf("there are no matching requests in %s", args[0]))
+		}
+
+		collectionPath := importCollectionPath(cmd, "")
+		for _, reqConfig := range result.Requests {
+			reqConfig.Collection = collectionPath
+		}
+		saveImportedRequests(cmd, result.Requests...)
+
+		printImportWarnings(result.Warnings)
+		fmt.Printf("%d requests were added to collection\n", len(result.Requests))
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importHARCmd)
+
+	importHARCmd.Flags().StringSliceP("domain", "d", []string{}, "Only imports the requests to these domains")
+	importHARCmd.Flags().StringSliceP("method", "m", []string{}, "Only imports the requests with these methods")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/openapi"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// importOpenAPICmd represents the import openapi command
+var importOpenAPICmd = &cobra.Command{
+	Use:     "openapi <spec>",
+	Aliases: []string{"swagger"},
+	Short:   "Creates a request for each operation of an OpenAPI spec",
+	Long: `Creates a request for each operation of an OpenAPI 3 or Swagger 2 spec,
+in YAML or JSON. The requests are added to the collection chosen with 
+--collection, or to a collection named after the title of the spec, with a 
+sub-collection for each tag.
+
+The server of the spec becomes the domain of the requests (it can be replaced
+with --server), path parameters become {{placeholders}}, and the examples of
+the parameters and request bodies are used as their values. Operations whose
+request body has several media types get a request for each one: the JSON (or
+else the form) body keeps the name of the operation, and the others are
+suffixed with their media type, e.g. "createPet-xml". The examples of
+the path parameters, and the credentials of the security schemes, are saved
+into an environment of the collection, "openapi" by default, which can be
+used with "httpmate run --env openapi".
+
+Example: httpmate import openapi spec.yaml --collection payments`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		cobra.CheckErr(err)
+
+		server, err := cmd.Flags().GetString("server")
+		cobra.CheckErr(err)
+
+		result, err := openapi.Import(data, server)
+		cobra.CheckErr(err)
+
+		if len(result.Requests) == 0 {
+			cobra.CheckErr(fmt.Errorf("there are no operations in %s", args[0]))
+		}
+
+		collectionPath := importCollectionPath(cmd, result.Name)
+		for _, reqConfig := range result.Requests {
+			reqConfig.Collection = filepath.Join(collectionPath, reqConfig.Collection)
+		}
+		saveImportedRequests(cmd, result.Requests...)
+
+		if len(result.Variables) > 0 {
+			environment, err := cmd.Flags().GetString("env")
+			cobra.CheckErr(err)
+
+			variables.SaveEnvironment(collectionPath, environment, result.Variables)
+			fmt.Printf("Variables were saved into the %q environment of the collection\n", environment)
+		}
+
+		printImportWarnings(result.Warnings)
+		fmt.Printf("%d requests were added to collection\n", len(result.Requests))
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importOpenAPICmd)
+
+	importOpenAPICmd.Flags().StringP("env", "e", "openapi", "Environment to which the variables of the spec will be saved")
+	importOpenAPICmd.Flags().StringP("server", "s", "", "Domain of the requests, instead of the servers of the spec")
+}
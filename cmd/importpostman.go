@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/postman"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+// importPostmanCmd represents the import postman command
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman <file>",
+	Short: "Imports the requests of a Postman collection",
+	Long: `Imports the requests of a Postman Collection (v2.1) export. The requests are
+added to the collection chosen with --collection, or to a collection named after
+the Postman collection. Postman folders become sub-collections.
+
+Postman {{variables}} are kept as they are. The collection variables and the
+values of path variables (/users/:id becomes /users/{{id}}) are saved into an
+environment of the collection, "postman" by default, which can be used with 
+"httpmate run --env postman". Anything that could not be translated, such as
+scripts, is reported.
+
+Example: httpmate import postman export.json --collection partners`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		cobra.CheckErr(err)
+
+		result, err := postman.Import(data)
+		cobra.CheckErr(err)
+
+		if len(result.Requests) == 0 {
+			cobra.CheckErr(fmt.Errorf("there are no requests in %s", args[0]))
+		}
+
+		collectionPath := importCollectionPath(cmd, result.Name)
+		for _, reqConfig := range result.Requests {
+			reqConfig.Collection = filepath.Join(collectionPath, reqConfig.Collection)
+		}
+		saveImportedRequests(cmd, result.Requests...)
+
+		if len(result.Variables) > 0 {
+			environment, err := cmd.Flags().GetString("env")
+			cobra.CheckErr(err)
+
+			variables.SaveEnvironment(collectionPath, environment, result.Variables)
+			fmt.Printf("Variables were saved into the %q environment of the collection\n", environment)
+		}
+
+		printImportWarnings(result.Warnings)
+		fmt.Printf("%d requests were added to collection\n", len(result.Requests))
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importPostmanCmd)
+
+	importPostmanCmd.Flags().StringP("env", "e", "postman", "Environment to which the Postman variables will be saved")
+}
@@ -5,15 +5,13 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/joaocgduarte/httpmate/internal/configs"
 	"github.com/joaocgduarte/httpmate/internal/prompts"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // removeCmd represents the remove command
 var removeCmd = &cobra.Command{
-	Use:   "remove",
+	Use:   "remove [collection/request]",
 	Short: "Removes a specific request",
 	Long: `Removes the configurations of a specific request with this command
 command. 
@@ -24,28 +22,17 @@ to remove only the request only inside a particullar collection
 Example: httpmate remove --collection "collection name"
 
 You can also specify the request which you want to remove directly, using the
---request flag. With this, you won't be prompted to select a request.`,
+--request flag or a "collection/request" argument. With this, you won't be
+prompted to select a request.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		collectionDir := viper.GetString("collectionDirectory")
-
-		chosenCollection, err := cmd.Flags().GetString("collection")
-		cobra.CheckErr(err)
-
-		if chosenCollection != "" {
-			collectionDir = filepath.Join(collectionDir, chosenCollection)
-		}
-
-		specifiedRequest, err := cmd.Flags().GetString("request")
-		cobra.CheckErr(err)
-
-		var reqConfig *configs.RequestConfig
-		if specifiedRequest == "" {
-			reqConfig = configs.PromptNewExistentRequestConfig(
-				"What is the request you want to perform?",
-				collectionDir,
-			)
-		} else {
-			reqConfig = configs.NewRequestConfigFromFilePath(filepath.Join(collectionDir, fmt.Sprintf("%s.json", specifiedRequest)))
+		reqConfig, _ := selectRequestConfig(
+			cmd,
+			args,
+			"What is the request you want to perform?",
+		)
+		if reqConfig.HTTPFile != "" {
+			cobra.CheckErr(fmt.Errorf("requests of .http files are removed by editing %s", reqConfig.HTTPFile))
 		}
 
 		confirm := prompts.ConfirmPrompt("Are you sure?")
@@ -54,7 +41,7 @@ You can also specify the request which you want to remove directly, using the
 			return
 		}
 
-		err = os.Remove(filepath.Join(reqConfig.Collection, fmt.Sprintf("%s.json", reqConfig.RequestName)))
+		err := os.Remove(filepath.Join(reqConfig.Collection, fmt.Sprintf("%s.json", reqConfig.RequestName)))
 		cobra.CheckErr(err)
 		fmt.Println("Request was removed successfully")
 	},
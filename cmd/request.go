@@ -1,23 +1,45 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/download"
+	"github.com/joaocgduarte/httpmate/internal/extract"
+	"github.com/joaocgduarte/httpmate/internal/filter"
+	"github.com/joaocgduarte/httpmate/internal/har"
+	"github.com/joaocgduarte/httpmate/internal/pretty"
 	"github.com/joaocgduarte/httpmate/internal/responseprinter"
+	"github.com/joaocgduarte/httpmate/internal/timing"
+	"github.com/joaocgduarte/httpmate/internal/variables"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 // runCmd represents the request command
 var runCmd = &cobra.Command{
-	Use:     "run",
 	Aliases: []string{"r"},
 	Short:   "Performs an HTTP request from your collection",
+	Use:     "run [collection/request]",
 	Long: `Performs an HTTP request. You will be prompted to choose your request.
 
+If you provide a collection, using the flag --collection, you would be able
+to choose only the requests inside a particular collection.
+
+You can also specify the request which you want to perform directly, using the
+--request flag or a "collection/request" argument. With this, you won't be 
+prompted to select a request, and the "alwaysEdit*" configurations are 
+ignored, so it can be used from scripts.
+
+Example: httpmate r "collection name/request name"
+
 You can add the "edit-*" flags to choose whether you want to edit particular
 details of the request before you make it. If these flags are true, you will 
 be prompted to edit any details you would want. Available "edit" flags are:
@@ -34,17 +56,67 @@ These flags are also available in the default configurations of the application.
 If these are set to true, you would always be prompted to edit the parameters
 you've chosen.
 
-Example: httpmate r --edit-body`,
+Example: httpmate r --edit-body
+
+Placeholders like {{domain}} in the request are replaced by the variables of
+the chosen environment. Environments are JSON files with key/value variables,
+stored under ".environments" in the collections directory (shared by every
+collection) or inside a collection directory (only for that collection). The
+environment is chosen with the --env flag, or with the "activeEnvironment"
+configuration by default.
+
+Example: httpmate r --env staging
+
+The executed request and its response (headers, body and timings) can be 
+recorded to a HAR file with the --har flag. If the file already exists, the
+request is added to its entries.
+
+Example: httpmate r --har bug-report.har
+
+Values of the response can be saved into variables with the "extract" section
+of the request (from a JSON path, a header, a regex over the body or a cookie),
+so the next requests can use them, e.g. as {{token}}. Extracted variables
+override the ones of the environment.
+
+With the --filter flag, only the results of a jq expression over the JSON body
+are printed, one per line, and the other messages are written to stderr. Use
+--raw-output to print strings without quotes. Unlike jq, objects keep their
+keys sorted rather than in the order of the body, so ".[]" over an object,
+"to_entries" and constructed objects like "{b, a}" follow the sorted keys.
+
+Example: httpmate r "collection/request" --filter '.items[] | select(.active) | .id'
+
+The --output flag chooses what is printed of the response:
+    * full: the status, headers, body and timings (default)
+    * body: only the body, exactly as received unless printed to a terminal
+    * headers: only the headers, one "Name: value" per line
+    * status: only the status, e.g. "200 OK"
+    * json: a JSON document with the request which was sent, the status, 
+      headers, body (base64 encoded if it's binary) and timings
+
+Other messages are written to stderr with every output except full.
+
+Example: httpmate r "collection/request" --output json
+
+Binary bodies (e.g. PDFs or images) are not printed, unless the body alone is
+redirected to a file or a pipe. To save the body to a file, use --save-body 
+with its path, or --save-dir with a directory, in which the file is named 
+after the Content-Disposition header or the URL of the response. The body is
+streamed to the file, with its progress shown on terminals.
+
+Example: httpmate r "collection/download" --save-dir ~/Downloads`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		reqConfig := configs.PromptNewExistentRequestConfig(
+		reqConfig, interactive := selectRequestConfig(
+			cmd,
+			args,
 			"What is the request you want to perform?",
-			viper.GetString("collectionDirectory"),
 		)
 
 		editFlagParser := func(cmdFlag, viperConfig string) bool {
 			editFlag, err := cmd.Flags().GetBool(cmdFlag)
 			cobra.CheckErr(err)
-			return viper.GetBool(viperConfig) || editFlag
+			return (interactive && viper.GetBool(viperConfig)) || editFlag
 
 		}
 		editConfigs := configs.EditRequestFlags{
@@ -59,29 +131,135 @@ Example: httpmate r --edit-body`,
 		}
 		reqConfig.PromptEditConfig(editConfigs)
 
+		environment, err := cmd.Flags().GetString("env")
+		cobra.CheckErr(err)
+		reqConfig = reqConfig.ResolveVariables(loadEnvironment(environment, reqConfig))
+
+		filterExpression, err := cmd.Flags().GetString("filter")
+		cobra.CheckErr(err)
+		rawOutput, err := cmd.Flags().GetBool("raw-output")
+		cobra.CheckErr(err)
+		waterfall, err := cmd.Flags().GetBool("waterfall")
+		cobra.CheckErr(err)
+		output, err := cmd.Flags().GetString("output")
+		cobra.CheckErr(err)
+		cobra.CheckErr(responseprinter.ValidateOutput(output))
+
+		printOptions := responseprinter.Options{Output: output, Waterfall: waterfall, RawOutput: rawOutput}
+		saveBody, err := cmd.Flags().GetString("save-body")
+		cobra.CheckErr(err)
+		saveDir, err := cmd.Flags().GetString("save-dir")
+		cobra.CheckErr(err)
+		if saveBody != "" && saveDir != "" {
+			cobra.CheckErr(fmt.Errorf("--save-body and --save-dir can't be used together"))
+		}
+		if saveDir != "" {
+			info, err := os.Stat(saveDir)
+			cobra.CheckErr(err)
+			if !info.IsDir() {
+				cobra.CheckErr(fmt.Errorf("%s is not a directory", saveDir))
+			}
+		}
+
+		if filterExpression != "" {
+			if output != responseprinter.OutputFull && output != responseprinter.OutputBody {
+				cobra.CheckErr(fmt.Errorf("--filter can't be used with the %s output", output))
+			}
+			if saveBody != "" || saveDir != "" {
+				cobra.CheckErr(fmt.Errorf("--filter can't be used when the body is saved"))
+			}
+			printOptions.Filter, err = filter.Parse(filterExpression)
+			cobra.CheckErr(err)
+		}
+
+		// Unless the full response is printed, only the output is written to
+		// stdout, so it can be piped to other commands.
+		messages := io.Writer(os.Stdout)
+		if output != responseprinter.OutputFull || printOptions.Filter != nil {
+			messages = os.Stderr
+		}
+
+		client := reqConfig.NewHTTPClient()
+
+		req := reqConfig.BuildHTTPRequest()
+
 		printCurl, err := cmd.Flags().GetBool("print-curl")
 		if printCurl {
-			fmt.Println("cURL equivalent:")
-			fmt.Println(reqConfig.ConvertToCurlCommand())
+			fmt.Fprintln(messages, "cURL equivalent:")
+			fmt.Fprintln(messages, reqConfig.ConvertToCurlCommand(req))
 		}
 
-		client := &http.Client{}
+		harPath, err := cmd.Flags().GetString("har")
+		cobra.CheckErr(err)
 
-		req := reqConfig.BuildHTTPRequest()
+		var requestBody, responseBody *har.BodyRecorder
+		if harPath != "" || output == responseprinter.OutputJSON {
+			requestBody = har.RecordBody(&req.Body)
+		}
 
-		fmt.Println("Request started...")
+		fmt.Fprintln(messages, "Request started...")
+		req, recorder := timing.Trace(req)
 		startTime := time.Now()
 		resp, err := client.Do(req)
 		cobra.CheckErr(err)
+		recorder.WrapBody(resp)
+
+		if harPath != "" {
+			// Saved bodies are streamed to their file, so only their size
+			// is recorded.
+			responseBody = har.RecordResponseBody(resp, saveBody != "" || saveDir != "")
+		}
+
+		var body []byte
+		switch {
+		case saveBody != "" || saveDir != "":
+			printOptions.SavedTo = saveResponseBody(messages, resp, saveBody, saveDir)
+			if len(reqConfig.Extract) > 0 {
+				body, err = os.ReadFile(printOptions.SavedTo)
+				cobra.CheckErr(err)
+			}
+		case len(reqConfig.Extract) > 0:
+			body, err = io.ReadAll(resp.Body)
+			cobra.CheckErr(err)
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		printOptions.RequestBody = requestBody.Bytes()
+		responseprinter.PrintHTTPResponse(resp, recorder, printOptions)
+
+		if len(reqConfig.Extract) > 0 {
+			saveExtractedVariables(messages, reqConfig, resp, body)
+		}
 
-		responseprinter.PrintHTTPResponse(resp, time.Since(startTime))
+		if harPath != "" {
+			entry := har.NewEntry(
+				req,
+				requestBody.Bytes(),
+				resp,
+				responseBody,
+				startTime,
+				har.NewTimings(recorder.Timings()),
+			)
+			cobra.CheckErr(har.AppendToFile(harPath, entry))
+			fmt.Fprintln(messages, "Request was recorded to", harPath)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 
+	runCmd.Flags().StringP("collection", "c", "", "Collection to which you will be prompted to perform a request")
+	runCmd.Flags().StringP("request", "r", "", "Specify the request which you want to perform, without being prompted")
+	runCmd.Flags().StringP("env", "e", "", "Environment whose variables will replace the {{placeholders}} of the request")
+	runCmd.Flags().String("har", "", "Records the request and its response to this HAR file")
 	runCmd.Flags().BoolP("print-curl", "p", false, "Will print a equivalent CURL request")
+	runCmd.Flags().Bool("waterfall", false, "Shows the timings of the request as a waterfall chart")
+	runCmd.Flags().StringP("output", "o", responseprinter.OutputFull, "What is printed of the response: "+strings.Join(responseprinter.Outputs, ", "))
+	runCmd.Flags().StringP("filter", "f", "", "Prints only the results of this jq expression over the JSON body, e.g. '.data.items[0].id'")
+	runCmd.Flags().Bool("raw-output", false, "Prints the strings returned by --filter without quotes")
+	runCmd.Flags().String("save-body", "", "Saves the response body to this file, instead of printing it")
+	runCmd.Flags().String("save-dir", "", "Saves the response body to this directory, named after its Content-Disposition or URL")
 	runCmd.Flags().BoolP("edit-body", "", false, "If set, you'll be asked to edit the body before making the request")
 	runCmd.Flags().BoolP("edit-domain", "", false, "If set, you'll be asked to edit the domain before making the request")
 	runCmd.Flags().BoolP("edit-path", "", false, "If set, you'll be asked to edit the path before making the request")
@@ -91,3 +269,66 @@ func init() {
 	runCmd.Flags().BoolP("edit-content-type", "", false, "If set, you'll be asked to edit the contentType before making the request")
 	runCmd.Flags().BoolP("edit-all", "", false, "If set, you'll be asked to edit all of the configuration before making the request")
 }
+
+func loadEnvironment(environment string, reqConfig *configs.RequestConfig) map[string]string {
+	if environment == "" {
+		environment = viper.GetString("activeEnvironment")
+	}
+
+	vars := variables.LoadEnvironment(
+		viper.GetString("collectionDirectory"),
+		reqConfig.Collection,
+		environment,
+	)
+	for key, value := range variables.LoadStore(viper.GetString("collectionDirectory")) {
+		vars[key] = value
+	}
+	return vars
+}
+
+// saveResponseBody streams the response body to the file, or to a file named
+// after the response in the directory, showing the progress on terminals. It
+// returns the path of the file.
+func saveResponseBody(messages io.Writer, resp *http.Response, filePath, directory string) string {
+	if directory != "" {
+		filePath = download.UniquePath(directory, download.FileName(resp))
+	}
+
+	var progress io.Writer
+	if pretty.IsTerminal(os.Stderr) {
+		progress = os.Stderr
+	}
+
+	size, err := download.Save(filePath, resp.Body, resp.ContentLength, progress)
+	cobra.CheckErr(err)
+	cobra.CheckErr(resp.Body.Close())
+	resp.Body = http.NoBody
+
+	fmt.Fprintf(messages, "Response body was saved to %s (%s)\n", filePath, download.FormatSize(size))
+	return filePath
+}
+
+// saveExtractedVariables saves the values extracted from the response into
+// the variable store, so they can be used by the next requests.
+func saveExtractedVariables(out io.Writer, reqConfig *configs.RequestConfig, resp *http.Response, body []byte) {
+	values, errs := extract.Apply(reqConfig.Extract, resp, body)
+	if len(errs) > 0 {
+		fmt.Fprintln(out, "Some values could not be extracted:")
+		for _, err := range errs {
+			fmt.Fprintf(out, "    %s\n", err)
+		}
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	variables.SaveToStore(viper.GetString("collectionDirectory"), values)
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "Saved variables:", strings.Join(names, ", "))
+}
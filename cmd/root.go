@@ -44,6 +44,12 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/httpmate/config.yaml)")
+	rootCmd.PersistentFlags().BoolP("insecure", "k", false, "Skips the verification of the TLS certificates of the servers")
+	viper.BindPFlag("tls.insecure", rootCmd.PersistentFlags().Lookup("insecure"))
+	// The flag overrides the proxy of the requests too, so it isn't bound to
+	// the "proxy" config.
+	rootCmd.PersistentFlags().String("proxy", "", "Sends the requests through this proxy (e.g. http://127.0.0.1:8080 or socks5://127.0.0.1:1080)")
+	viper.BindPFlag("proxyFlag", rootCmd.PersistentFlags().Lookup("proxy"))
 }
 
 func initConfig() {
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/reports"
+	"github.com/joaocgduarte/httpmate/internal/testrunner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:     "test [collection]",
+	Aliases: []string{"t"},
+	Short:   "Runs every request of a collection and checks its assertions",
+	Long: `Runs every request of a collection, evaluating the "assertions" of each
+request, and prints a summary with the requests that passed and failed. If no
+collection is provided, every request of every collection is run.
+
+The command exits with a non-zero code if any request fails, so it can be used
+as a smoke test in CI. Requests without assertions pass as long as a response
+is received. Available assertions are:
+
+    "assertions": {
+        "status": 200,
+        "headers": [{"name": "Content-Type", "contains": "json"}],
+        "json_path": [{"path": "$.data.id", "equals": 1}, {"path": "$.error", "exists": false}],
+        "body_regex": ["\"name\":\\s*\"httpmate\""],
+        "max_latency_ms": 500
+    }
+
+Example: httpmate test "collection name" --env staging
+
+Machine-readable reports can be written with the --report flag, which accepts
+"junit" and "tap" formats, optionally followed by "=path" to write the report
+to a file instead of stdout. It can be repeated to write several reports, of
+which only one can be written to stdout.
+
+Example: httpmate test "collection name" --report junit=report.xml --report tap`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		collectionsPath := viper.GetString("collectionDirectory")
+
+		collection := ""
+		if len(args) > 0 {
+			collection = args[0]
+		}
+
+		requests := files.GetFilesFromDirectoryWithoutExtension(filepath.Join(collectionsPath, collection))
+		if len(requests) == 0 {
+			cobra.CompError("There are no available requests in collection")
+			os.Exit(-1)
+		}
+
+		environment, err := cmd.Flags().GetString("env")
+		cobra.CheckErr(err)
+
+		reportDefinitions, err := cmd.Flags().GetStringArray("report")
+		cobra.CheckErr(err)
+
+		testReports, err := reports.ParseReports(reportDefinitions)
+		cobra.CheckErr(err)
+		quiet := false
+		for _, report := range testReports {
+			quiet = quiet || report.IsStdout()
+		}
+
+		results := make([]*testrunner.CaseResult, 0, len(requests))
+		for _, request := range requests {
+			reqConfig, err := configs.FindRequestConfig(collectionsPath, collection, request)
+			cobra.CheckErr(err)
+			reqConfig = reqConfig.ResolveVariables(loadEnvironment(environment, reqConfig))
+
+			var result *testrunner.CaseResult
+			if client, err := reqConfig.HTTPClient(); err != nil {
+				result = testrunner.NewErrorResult(reqConfig, fmt.Errorf("invalid request: %w", err))
+			} else {
+				result = testrunner.Run(client, reqConfig)
+			}
+			if !quiet {
+				printTestCaseResult(result)
+			}
+			results = append(results, result)
+		}
+
+		suiteName := collection
+		if suiteName == "" {
+			suiteName = projectName
+		}
+		for _, report := range testReports {
+			cobra.CheckErr(report.Write(suiteName, results))
+		}
+
+		if !quiet {
+			failed := testrunner.CountFailed(results)
+			fmt.Printf("\n%d passed, %d failed, %d total\n", len(results)-failed, failed, len(results))
+		}
+		if code := testrunner.ExitCode(results); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func printTestCaseResult(result *testrunner.CaseResult) {
+	outcome := "PASS"
+	if !result.Passed() {
+		outcome = "FAIL"
+	}
+
+	status := result.Status
+	if result.Err != nil {
+		status = "no response"
+	}
+
+	fmt.Printf("%s %s (%s, %s)\n", outcome, result.Name(), status, result.Duration)
+	for _, failure := range result.Failures() {
+		fmt.Printf("    %s\n", failure)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().StringArray("report", []string{}, "Writes a report of the run, as \"junit[=path]\" or \"tap[=path]\"")
+	testCmd.Flags().StringP("env", "e", "", "Environment whose variables will replace the {{placeholders}} of the requests")
+}
@@ -0,0 +1,175 @@
+package assertions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/jsonpath"
+)
+
+type HeaderAssertion struct {
+	Name     string  `json:"name"`
+	Equals   *string `json:"equals,omitempty"`
+	Contains *string `json:"contains,omitempty"`
+}
+
+type JSONPathAssertion struct {
+	Path   string          `json:"path"`
+	Equals json.RawMessage `json:"equals,omitempty"`
+	Exists *bool           `json:"exists,omitempty"`
+}
+
+type Assertions struct {
+	Status       *int                `json:"status,omitempty"`
+	Headers      []HeaderAssertion   `json:"headers,omitempty"`
+	JSONPath     []JSONPathAssertion `json:"json_path,omitempty"`
+	BodyRegex    []string            `json:"body_regex,omitempty"`
+	MaxLatencyMS *int64              `json:"max_latency_ms,omitempty"`
+}
+
+type Result struct {
+	Assertion string
+	Passed    bool
+	Message   string
+}
+
+// Evaluate checks every assertion against the response. The body is passed
+// separately since the response body has already been consumed.
+func (a *Assertions) Evaluate(resp *http.Response, body []byte, latency time.Duration) []Result {
+	results := make([]Result, 0)
+	if a == nil {
+		return results
+	}
+
+	if a.Status != nil {
+		results = append(results, newResult(
+			fmt.Sprintf("status is %d", *a.Status),
+			resp.StatusCode == *a.Status,
+			fmt.Sprintf("expected status %d, got %d", *a.Status, resp.StatusCode),
+		))
+	}
+
+	for _, header := range a.Headers {
+		results = append(results, header.evaluate(resp.Header)...)
+	}
+
+	for _, path := range a.JSONPath {
+		results = append(results, path.evaluate(body)...)
+	}
+
+	for _, expression := range a.BodyRegex {
+		results = append(results, evaluateBodyRegex(expression, body))
+	}
+
+	if a.MaxLatencyMS != nil {
+		maxLatency := time.Duration(*a.MaxLatencyMS) * time.Millisecond
+		results = append(results, newResult(
+			fmt.Sprintf("latency is at most %s", maxLatency),
+			latency <= maxLatency,
+			fmt.Sprintf("expected latency of at most %s, got %s", maxLatency, latency),
+		))
+	}
+
+	return results
+}
+
+func (h HeaderAssertion) evaluate(headers http.Header) []Result {
+	results := make([]Result, 0)
+	values, ok := headers[http.CanonicalHeaderKey(h.Name)]
+	value := strings.Join(values, ", ")
+
+	if h.Equals == nil && h.Contains == nil {
+		results = append(results, newResult(
+			fmt.Sprintf("header %s exists", h.Name),
+			ok,
+			fmt.Sprintf("expected header %s to exist", h.Name),
+		))
+	}
+
+	if h.Equals != nil {
+		results = append(results, newResult(
+			fmt.Sprintf("header %s equals %q", h.Name, *h.Equals),
+			ok && value == *h.Equals,
+			fmt.Sprintf("expected header %s to equal %q, got %q", h.Name, *h.Equals, value),
+		))
+	}
+
+	if h.Contains != nil {
+		results = append(results, newResult(
+			fmt.Sprintf("header %s contains %q", h.Name, *h.Contains),
+			ok && strings.Contains(value, *h.Contains),
+			fmt.Sprintf("expected header %s to contain %q, got %q", h.Name, *h.Contains, value),
+		))
+	}
+
+	return results
+}
+
+func (j JSONPathAssertion) evaluate(body []byte) []Result {
+	results := make([]Result, 0)
+	value, err := jsonpath.Lookup(body, j.Path)
+	found := err == nil
+
+	if err != nil && !errors.Is(err, jsonpath.ErrNotFound) {
+		return append(results, Result{
+			Assertion: fmt.Sprintf("json path %s", j.Path),
+			Message:   err.Error(),
+		})
+	}
+
+	if j.Exists != nil || j.Equals == nil {
+		shouldExist := j.Exists == nil || *j.Exists
+		results = append(results, newResult(
+			fmt.Sprintf("json path %s exists: %t", j.Path, shouldExist),
+			found == shouldExist,
+			fmt.Sprintf("expected json path %s exists to be %t, got %t", j.Path, shouldExist, found),
+		))
+	}
+
+	if j.Equals != nil {
+		var expected interface{}
+		if err := json.Unmarshal(j.Equals, &expected); err != nil {
+			return append(results, Result{
+				Assertion: fmt.Sprintf("json path %s equals %s", j.Path, j.Equals),
+				Message:   fmt.Sprintf("invalid expected value: %s", err),
+			})
+		}
+
+		actual, _ := json.Marshal(value)
+		results = append(results, newResult(
+			fmt.Sprintf("json path %s equals %s", j.Path, j.Equals),
+			found && reflect.DeepEqual(expected, value),
+			fmt.Sprintf("expected json path %s to equal %s, got %s", j.Path, j.Equals, actual),
+		))
+	}
+
+	return results
+}
+
+func evaluateBodyRegex(expression string, body []byte) Result {
+	assertion := fmt.Sprintf("body matches %q", expression)
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return Result{Assertion: assertion, Message: fmt.Sprintf("invalid regex: %s", err)}
+	}
+
+	return newResult(
+		assertion,
+		regex.Match(body),
+		fmt.Sprintf("expected body to match %q", expression),
+	)
+}
+
+func newResult(assertion string, passed bool, failureMessage string) Result {
+	result := Result{Assertion: assertion, Passed: passed}
+	if !passed {
+		result.Message = failureMessage
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package assertions
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func boolPointer(value bool) *bool {
+	return &value
+}
+
+func TestEvaluate(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": []string{"application/json; charset=utf-8"},
+		},
+	}
+	body := []byte(`{"data": {"id": 1, "name": "httpmate", "tags": ["cli"]}, "error": null}`)
+	latency := 120 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		assertions Assertions
+		passed     bool
+	}{
+		{
+			name:       "status passes",
+			assertions: Assertions{Status: intPointer(200)},
+			passed:     true,
+		},
+		{
+			name:       "status fails",
+			assertions: Assertions{Status: intPointer(201)},
+			passed:     false,
+		},
+		{
+			name:       "header exists passes",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "content-type"}}},
+			passed:     true,
+		},
+		{
+			name:       "header exists fails",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "X-Request-Id"}}},
+			passed:     false,
+		},
+		{
+			name:       "header equals passes",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "Content-Type", Equals: stringPointer("application/json; charset=utf-8")}}},
+			passed:     true,
+		},
+		{
+			name:       "header equals fails",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "Content-Type", Equals: stringPointer("application/json")}}},
+			passed:     false,
+		},
+		{
+			name:       "header contains passes",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "Content-Type", Contains: stringPointer("json")}}},
+			passed:     true,
+		},
+		{
+			name:       "header contains fails",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "Content-Type", Contains: stringPointer("xml")}}},
+			passed:     false,
+		},
+		{
+			name:       "header contains fails when missing",
+			assertions: Assertions{Headers: []HeaderAssertion{{Name: "X-Missing", Contains: stringPointer("")}}},
+			passed:     false,
+		},
+		{
+			name:       "json path equals number passes",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.id", Equals: json.RawMessage(`1`)}}},
+			passed:     true,
+		},
+		{
+			name:       "json path equals array passes",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "data.tags", Equals: json.RawMessage(`["cli"]`)}}},
+			passed:     true,
+		},
+		{
+			name:       "json path equals fails",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.name", Equals: json.RawMessage(`"other"`)}}},
+			passed:     false,
+		},
+		{
+			name:       "json path equals fails when missing",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.missing", Equals: json.RawMessage(`null`)}}},
+			passed:     false,
+		},
+		{
+			name:       "json path equals fails with an invalid expected value",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.id", Equals: json.RawMessage(`{`)}}},
+			passed:     false,
+		},
+		{
+			name:       "json path exists passes",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.name"}}},
+			passed:     true,
+		},
+		{
+			name:       "json path exists fails",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.missing", Exists: boolPointer(true)}}},
+			passed:     false,
+		},
+		{
+			name:       "json path doesn't exist passes",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.missing", Exists: boolPointer(false)}}},
+			passed:     true,
+		},
+		{
+			name:       "json path doesn't exist fails",
+			assertions: Assertions{JSONPath: []JSONPathAssertion{{Path: "$.data.id", Exists: boolPointer(false)}}},
+			passed:     false,
+		},
+		{
+			name:       "body regex passes",
+			assertions: Assertions{BodyRegex: []string{`"name":\s*"httpmate"`}},
+			passed:     true,
+		},
+		{
+			name:       "body regex fails",
+			assertions: Assertions{BodyRegex: []string{`"name":\s*"other"`}},
+			passed:     false,
+		},
+		{
+			name:       "body regex fails when invalid",
+			assertions: Assertions{BodyRegex: []string{`(`}},
+			passed:     false,
+		},
+		{
+			name:       "max latency passes",
+			assertions: Assertions{MaxLatencyMS: int64Pointer(120)},
+			passed:     true,
+		},
+		{
+			name:       "max latency fails",
+			assertions: Assertions{MaxLatencyMS: int64Pointer(100)},
+			passed:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := test.assertions.Evaluate(resp, body, latency)
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d: %+v", len(results), results)
+			}
+
+			result := results[0]
+			if result.Passed != test.passed {
+				t.Errorf("expected passed to be %t, got %t (%s)", test.passed, result.Passed, result.Message)
+			}
+			if result.Assertion == "" {
+				t.Error("expected the assertion to be described")
+			}
+			if !result.Passed && result.Message == "" {
+				t.Error("expected a failure message")
+			}
+			if result.Passed && result.Message != "" {
+				t.Errorf("expected no message on success, got %q", result.Message)
+			}
+		})
+	}
+}
+
+func TestEvaluateWithoutAssertions(t *testing.T) {
+	var assertions *Assertions
+	results := assertions.Evaluate(&http.Response{StatusCode: http.StatusInternalServerError}, nil, 0)
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+}
+
+func TestEvaluateHeaderEqualsAndContains(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"X-Version": []string{"1.2.3"}}}
+	assertions := &Assertions{Headers: []HeaderAssertion{{
+		Name:     "X-Version",
+		Equals:   stringPointer("1.2.3"),
+		Contains: stringPointer("4"),
+	}}}
+
+	results := assertions.Evaluate(resp, nil, 0)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(results), results)
+	}
+	if !results[0].Passed {
+		t.Errorf("expected equals to pass: %s", results[0].Message)
+	}
+	if results[1].Passed {
+		t.Error("expected contains to fail")
+	}
+}
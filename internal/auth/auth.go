@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeBasic    Type = "basic"
+	TypeBearer   Type = "bearer"
+	TypeAPIKey   Type = "apikey"
+	TypeDigest   Type = "digest"
+	TypeOAuth2   Type = "oauth2"
+	TypeAWSSigV4 Type = "aws_sigv4"
+)
+
+// Types are the available authentication types, in the order in which they
+// are prompted.
+var Types = []Type{TypeBasic, TypeBearer, TypeAPIKey, TypeDigest, TypeOAuth2, TypeAWSSigV4}
+
+const (
+	InHeader = "header"
+	InQuery  = "query"
+)
+
+// Auth is the authentication of a request. Only the fields of its type are
+// used:
+//   - basic and digest: username and password
+//   - bearer: token
+//   - apikey: key, value and in ("header", the default, or "query")
+//   - oauth2: token_url, client_id, client_secret, scopes, client_auth
+//     ("header", the default, or "body") and grant_type, which requires
+//     username and password for "password", or refresh_token for
+//     "refresh_token"
+//   - aws_sigv4: access_key, secret_key, session_token (optional), region
+//     and service
+type Auth struct {
+	Type         Type     `json:"type"`
+	Username     string   `json:"username,omitempty"`
+	Password     string   `json:"password,omitempty"`
+	Token        string   `json:"token,omitempty"`
+	Key          string   `json:"key,omitempty"`
+	Value        string   `json:"value,omitempty"`
+	In           string   `json:"in,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	ClientAuth   string   `json:"client_auth,omitempty"`
+	GrantType    string   `json:"grant_type,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	AccessKey    string   `json:"access_key,omitempty"`
+	SecretKey    string   `json:"secret_key,omitempty"`
+	SessionToken string   `json:"session_token,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	Service      string   `json:"service,omitempty"`
+}
+
+// Resolve returns a copy of the auth with every field resolved, e.g. with its
+// {{variables}} replaced.
+func (a *Auth) Resolve(resolve func(string) string) *Auth {
+	if a == nil {
+		return nil
+	}
+
+	resolved := &Auth{
+		Type:         a.Type,
+		Username:     resolve(a.Username),
+		Password:     resolve(a.Password),
+		Token:        resolve(a.Token),
+		Key:          resolve(a.Key),
+		Value:        resolve(a.Value),
+		In:           a.In,
+		TokenURL:     resolve(a.TokenURL),
+		ClientID:     resolve(a.ClientID),
+		ClientSecret: resolve(a.ClientSecret),
+		ClientAuth:   a.ClientAuth,
+		GrantType:    a.GrantType,
+		RefreshToken: resolve(a.RefreshToken),
+		AccessKey:    resolve(a.AccessKey),
+		SecretKey:    resolve(a.SecretKey),
+		SessionToken: resolve(a.SessionToken),
+		Region:       resolve(a.Region),
+		Service:      resolve(a.Service),
+	}
+	for _, scope := range a.Scopes {
+		resolved.Scopes = append(resolved.Scopes, resolve(scope))
+	}
+	return resolved
+}
+
+// Validate checks that the auth has a known type and the fields it requires.
+func (a *Auth) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Type {
+	case TypeBasic, TypeDigest:
+		if a.Username == "" {
+			return fmt.Errorf("%s auth requires a username", a.Type)
+		}
+	case TypeBearer:
+		if a.Token == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	case TypeAPIKey:
+		if a.Key == "" {
+			return fmt.Errorf("apikey auth requires a key")
+		}
+		if a.In != "" && a.In != InHeader && a.In != InQuery {
+			return fmt.Errorf("apikey auth must be in %q or %q, got %q", InHeader, InQuery, a.In)
+		}
+	case TypeOAuth2:
+		return a.validateOAuth2()
+	case TypeAWSSigV4:
+		if a.AccessKey == "" || a.SecretKey == "" || a.Region == "" || a.Service == "" {
+			return fmt.Errorf("aws_sigv4 auth requires an access_key, a secret_key, a region and a service")
+		}
+	default:
+		return fmt.Errorf("unknown auth type %q", a.Type)
+	}
+	return nil
+}
+
+func (a *Auth) validateOAuth2() error {
+	if a.TokenURL == "" || a.ClientID == "" {
+		return fmt.Errorf("oauth2 auth requires a token_url and a client_id")
+	}
+	if a.ClientAuth != "" && a.ClientAuth != ClientAuthHeader && a.ClientAuth != ClientAuthBody {
+		return fmt.Errorf("oauth2 client_auth must be %q or %q, got %q", ClientAuthHeader, ClientAuthBody, a.ClientAuth)
+	}
+
+	switch a.GrantType {
+	case GrantTypeClientCredentials:
+	case GrantTypePassword:
+		if a.Username == "" {
+			return fmt.Errorf("oauth2 password grant requires a username")
+		}
+	case GrantTypeRefreshToken:
+		if a.RefreshToken == "" {
+			return fmt.Errorf("oauth2 refresh_token grant requires a refresh_token")
+		}
+	default:
+		return fmt.Errorf("unknown oauth2 grant_type %q", a.GrantType)
+	}
+	return nil
+}
+
+// Apply authenticates the request. OAuth2 tokens are requested with
+// tokenClient and cached in tokenCacheDirectory. AWS signatures cover the whole request, so Apply must
+// be the last change to the request. Digest authentication needs a challenge
+// from the server, so it's applied by the Transport instead.
+func (a *Auth) Apply(req *http.Request, tokenClient *http.Client, tokenCacheDirectory string) error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Type {
+	case TypeBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case TypeBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case TypeAPIKey:
+		if a.In == InQuery {
+			query := req.URL.Query()
+			query.Set(a.Key, a.Value)
+			req.URL.RawQuery = query.Encode()
+			return nil
+		}
+		req.Header.Set(a.Key, a.Value)
+	case TypeOAuth2:
+		token, err := a.OAuth2Token(tokenClient, tokenCacheDirectory)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case TypeAWSSigV4:
+		return a.signV4(req, time.Now())
+	}
+	return nil
+}
+
+// Transport wraps the base transport with the authentication which is
+// negotiated with the server, if any.
+func (a *Auth) Transport(base http.RoundTripper) http.RoundTripper {
+	if a == nil || a.Type != TypeDigest {
+		return base
+	}
+	return &digestTransport{base: base, username: a.Username, password: a.Password}
+}
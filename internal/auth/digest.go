@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// digestTransport performs the digest authentication (RFC 7616): requests are
+// sent without credentials and, if the server replies with a digest
+// challenge, they are sent again with the response to the challenge.
+type digestTransport struct {
+	base     http.RoundTripper
+	username string
+	password string
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge, ok := findDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+
+	// The body was already sent, so it can only be sent again if it can be
+	// recreated (which is not the case of files).
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+	}
+
+	authorization, err := challenge.authorization(t.username, t.password, req.Method, req.URL.RequestURI())
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retry.Header.Set("Authorization", authorization)
+	return t.base.RoundTrip(retry)
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       []string
+}
+
+func findDigestChallenge(headers []string) (*digestChallenge, bool) {
+	for _, header := range headers {
+		scheme, params, found := strings.Cut(strings.TrimSpace(header), " ")
+		if !found || !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		values := parseParams(params)
+		challenge := &digestChallenge{
+			realm:     values["realm"],
+			nonce:     values["nonce"],
+			opaque:    values["opaque"],
+			algorithm: values["algorithm"],
+		}
+		for _, qop := range strings.Split(values["qop"], ",") {
+			if qop = strings.TrimSpace(qop); qop != "" {
+				challenge.qop = append(challenge.qop, qop)
+			}
+		}
+		return challenge, true
+	}
+	return nil, false
+}
+
+// parseParams parses the comma separated key=value (or key="value") params of
+// a challenge.
+func parseParams(params string) map[string]string {
+	result := map[string]string{}
+	for params != "" {
+		params = strings.TrimLeft(params, " ,")
+		key, rest, found := strings.Cut(params, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				result[key] = rest[1:]
+				break
+			}
+			value, params = rest[1:end+1], rest[end+2:]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+func (c *digestChallenge) authorization(username, password, method, uri string) (string, error) {
+	algorithm := strings.ToUpper(c.algorithm)
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", c.algorithm)
+	}
+	digest := func(data string) string {
+		h := newHash()
+		h.Write([]byte(data))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	qop := ""
+	for _, offered := range c.qop {
+		if offered == "auth" {
+			qop = offered
+		}
+	}
+	if qop == "" && len(c.qop) > 0 {
+		// Only "auth-int" is offered, which is not supported.
+		return "", fmt.Errorf("unsupported digest qop %q", strings.Join(c.qop, ","))
+	}
+
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", err
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+
+	ha1 := digest(fmt.Sprintf("%s:%s:%s", username, c.realm, password))
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = digest(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, cnonce))
+	}
+	ha2 := digest(fmt.Sprintf("%s:%s", method, uri))
+
+	var response string
+	if qop == "" {
+		response = digest(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, ha2))
+	} else {
+		response = digest(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, c.nonce, nc, cnonce, qop, ha2))
+	}
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf("algorithm=%s", algorithm),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if qop != "" {
+		params = append(params, fmt.Sprintf("qop=%s", qop), fmt.Sprintf("nc=%s", nc), fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
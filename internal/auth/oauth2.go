@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypePassword          = "password"
+	GrantTypeRefreshToken      = "refresh_token"
+
+	ClientAuthHeader = "header"
+	ClientAuthBody   = "body"
+
+	tokenCacheDirectoryName = "oauth2"
+	// Tokens are renewed a bit before they expire, so they don't expire
+	// while the request is being sent.
+	expiryMargin = 30 * time.Second
+)
+
+// cachedToken is the token response of the server, as stored in the cache.
+type cachedToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (t *cachedToken) valid() bool {
+	return t.AccessToken != "" && time.Now().Add(expiryMargin).Before(t.ExpiresAt)
+}
+
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// OAuth2Token returns the access token of an oauth2 auth. Tokens are cached
+// in cacheDirectory until they expire, and then they are renewed with their
+// refresh token, if the server returned one, or with the grant type of the
+// auth. The tokens are requested with client, so they go through the same
+// TLS and proxy settings as the request.
+func (a *Auth) OAuth2Token(client *http.Client, cacheDirectory string) (string, error) {
+	cachePath := a.tokenCachePath(cacheDirectory)
+
+	cached, err := readCachedToken(cachePath)
+	if err != nil {
+		return "", err
+	}
+	if cached != nil && cached.valid() {
+		return cached.AccessToken, nil
+	}
+
+	var token *cachedToken
+	if cached != nil && cached.RefreshToken != "" {
+		// If the refresh token was revoked or expired, a new token is
+		// requested with the grant type of the auth.
+		token, _ = a.requestToken(client, url.Values{
+			"grant_type":    {GrantTypeRefreshToken},
+			"refresh_token": {cached.RefreshToken},
+		})
+	}
+
+	if token == nil {
+		token, err = a.requestToken(client, a.grantParams())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if token.RefreshToken == "" && cached != nil {
+		token.RefreshToken = cached.RefreshToken
+	}
+	if err := writeCachedToken(cachePath, token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// CachedOAuth2Token returns the access token of an oauth2 auth from the
+// cache, without requesting one. It returns false if there's no valid token
+// in the cache.
+func (a *Auth) CachedOAuth2Token(cacheDirectory string) (string, bool) {
+	cached, err := readCachedToken(a.tokenCachePath(cacheDirectory))
+	if err != nil || cached == nil || !cached.valid() {
+		return "", false
+	}
+	return cached.AccessToken, true
+}
+
+func (a *Auth) grantParams() url.Values {
+	params := url.Values{"grant_type": {a.GrantType}}
+	switch a.GrantType {
+	case GrantTypePassword:
+		params.Set("username", a.Username)
+		params.Set("password", a.Password)
+	case GrantTypeRefreshToken:
+		params.Set("refresh_token", a.RefreshToken)
+	}
+	if len(a.Scopes) > 0 {
+		params.Set("scope", strings.Join(a.Scopes, " "))
+	}
+	return params
+}
+
+func (a *Auth) requestToken(client *http.Client, params url.Values) (*cachedToken, error) {
+	if a.ClientAuth == ClientAuthBody {
+		params.Set("client_id", a.ClientID)
+		if a.ClientSecret != "" {
+			params.Set("client_secret", a.ClientSecret)
+		}
+	} else if a.ClientSecret == "" {
+		// Public clients only identify themselves.
+		params.Set("client_id", a.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if a.ClientAuth != ClientAuthBody && a.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+	}
+
+	requestTime := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+
+	var response tokenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("oauth2 token request failed: %s, invalid response: %s", resp.Status, body)
+	}
+	if resp.StatusCode != http.StatusOK || response.AccessToken == "" {
+		message := strings.TrimSpace(fmt.Sprintf("%s %s", response.Error, response.ErrorDescription))
+		if message == "" {
+			message = string(body)
+		}
+		return nil, fmt.Errorf("oauth2 token request failed: %s, %s", resp.Status, message)
+	}
+
+	// Tokens without expiration are not reused, unless they can be
+	// refreshed.
+	token := &cachedToken{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		ExpiresAt:    requestTime,
+	}
+	if expiresIn, err := response.ExpiresIn.Int64(); err == nil {
+		token.ExpiresAt = requestTime.Add(time.Duration(expiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func (a *Auth) tokenCachePath(cacheDirectory string) string {
+	return filepath.Join(cacheDirectory, tokenCacheDirectoryName, a.tokenCacheKey()+".json")
+}
+
+// tokenCacheKey identifies the tokens of the same client and user, for the
+// same scopes.
+func (a *Auth) tokenCacheKey() string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		a.TokenURL,
+		a.ClientID,
+		a.GrantType,
+		a.Username,
+		strings.Join(a.Scopes, " "),
+	}, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+func readCachedToken(path string) (*cachedToken, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token cachedToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		// A corrupted cache is replaced by a new token.
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// writeCachedToken stores the token, readable only by the user since it's a
+// credential.
+func writeCachedToken(path string, token *cachedToken) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,436 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenServer is an oauth2 token endpoint which records the requests it
+// receives.
+type tokenServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []url.Values
+	expiresIn int
+	refresh   string
+}
+
+func newTokenServer(t *testing.T, expiresIn int, refreshToken string) *tokenServer {
+	t.Helper()
+
+	server := &tokenServer{expiresIn: expiresIn, refresh: refreshToken}
+	server.Server = httptest.NewTLSServer(http.HandlerFunc(server.handle))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *tokenServer) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	form := r.PostForm
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		form.Set("basic_auth", clientID+":"+clientSecret)
+	}
+	s.requests = append(s.requests, form)
+
+	if form.Get("grant_type") == GrantTypePassword && form.Get("password") != "secret" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "wrong password",
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"access_token": fmt.Sprintf("token-%d", len(s.requests)),
+		"token_type":   "Bearer",
+		"expires_in":   s.expiresIn,
+	}
+	if s.refresh != "" {
+		response["refresh_token"] = s.refresh
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *tokenServer) grants() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values{}, s.requests...)
+}
+
+func TestOAuth2TokenGrantTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     Auth
+		expected url.Values
+	}{
+		{
+			name: "client credentials",
+			auth: Auth{
+				GrantType:    GrantTypeClientCredentials,
+				ClientSecret: "client-secret",
+				Scopes:       []string{"read", "write"},
+			},
+			expected: url.Values{
+				"grant_type": {GrantTypeClientCredentials},
+				"scope":      {"read write"},
+				"basic_auth": {"client:client-secret"},
+			},
+		},
+		{
+			name: "client credentials with the client in the body",
+			auth: Auth{
+				GrantType:    GrantTypeClientCredentials,
+				ClientSecret: "client-secret",
+				ClientAuth:   ClientAuthBody,
+			},
+			expected: url.Values{
+				"grant_type":    {GrantTypeClientCredentials},
+				"client_id":     {"client"},
+				"client_secret": {"client-secret"},
+			},
+		},
+		{
+			name: "password",
+			auth: Auth{
+				GrantType: GrantTypePassword,
+				Username:  "user",
+				Password:  "secret",
+			},
+			expected: url.Values{
+				"grant_type": {GrantTypePassword},
+				"username":   {"user"},
+				"password":   {"secret"},
+				"client_id":  {"client"},
+			},
+		},
+		{
+			name: "refresh token",
+			auth: Auth{
+				GrantType:    GrantTypeRefreshToken,
+				RefreshToken: "configured-refresh-token",
+			},
+			expected: url.Values{
+				"grant_type":    {GrantTypeRefreshToken},
+				"refresh_token": {"configured-refresh-token"},
+				"client_id":     {"client"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTokenServer(t, 3600, "")
+			a := test.auth
+			a.Type = TypeOAuth2
+			a.TokenURL = server.URL
+			a.ClientID = "client"
+
+			token, err := a.OAuth2Token(server.Client(), t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != "token-1" {
+				t.Errorf("unexpected token %q", token)
+			}
+
+			grants := server.grants()
+			if len(grants) != 1 {
+				t.Fatalf("expected 1 token request, got %d", len(grants))
+			}
+			if grants[0].Encode() != test.expected.Encode() {
+				t.Errorf("expected the token request %q, got %q", test.expected.Encode(), grants[0].Encode())
+			}
+		})
+	}
+}
+
+func TestOAuth2TokenError(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypePassword,
+		Username:  "user",
+		Password:  "wrong",
+	}
+
+	_, err := a.OAuth2Token(server.Client(), t.TempDir())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "oauth2 token request failed: 400 Bad Request, invalid_grant wrong password"
+	if err.Error() != expected {
+		t.Errorf("expected the error %q, got %q", expected, err)
+	}
+}
+
+func TestOAuth2TokenUsesTheClient(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypeClientCredentials,
+	}
+
+	// The server's certificate is only trusted by its own client.
+	if _, err := a.OAuth2Token(&http.Client{}, t.TempDir()); err == nil {
+		t.Fatal("expected the certificate of the server not to be trusted")
+	}
+	if len(server.grants()) != 0 {
+		t.Errorf("expected no token request, got %d", len(server.grants()))
+	}
+}
+
+func TestOAuth2TokenIsCached(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypeClientCredentials,
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token-1" {
+			t.Errorf("expected the cached token, got %q", token)
+		}
+	}
+
+	if len(server.grants()) != 1 {
+		t.Errorf("expected 1 token request, got %d", len(server.grants()))
+	}
+
+	// Other scopes don't share the token.
+	a.Scopes = []string{"admin"}
+	token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-2" {
+		t.Errorf("expected a new token for other scopes, got %q", token)
+	}
+}
+
+func TestOAuth2TokenIsRenewedBeforeExpiry(t *testing.T) {
+	// The token expires within the expiry margin, so it's never reused.
+	server := newTokenServer(t, int((expiryMargin - 5*time.Second).Seconds()), "")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypeClientCredentials,
+	}
+
+	for i, expected := range []string{"token-1", "token-2"} {
+		token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != expected {
+			t.Errorf("request %d: expected %q, got %q", i, expected, token)
+		}
+	}
+}
+
+func TestOAuth2TokenIsRenewedAfterExpiry(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypeClientCredentials,
+	}
+	cachePath := a.tokenCachePath(cacheDirectory)
+
+	// Still valid after the expiry margin.
+	err := writeCachedToken(cachePath, &cachedToken{
+		AccessToken: "cached",
+		ExpiresAt:   time.Now().Add(expiryMargin + time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "cached" {
+		t.Errorf("expected the cached token, got %q", token)
+	}
+
+	// Expires within the expiry margin.
+	err = writeCachedToken(cachePath, &cachedToken{
+		AccessToken: "cached",
+		ExpiresAt:   time.Now().Add(expiryMargin - time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err = a.OAuth2Token(server.Client(), cacheDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-1" {
+		t.Errorf("expected a new token, got %q", token)
+	}
+
+	cached, err := readCachedToken(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.AccessToken != "token-1" || !cached.valid() {
+		t.Errorf("expected the new token to be cached, got %+v", cached)
+	}
+}
+
+func TestOAuth2TokenUsesRefreshToken(t *testing.T) {
+	server := newTokenServer(t, 0, "server-refresh-token")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:         TypeOAuth2,
+		TokenURL:     server.URL,
+		ClientID:     "client",
+		GrantType:    GrantTypePassword,
+		Username:     "user",
+		Password:     "secret",
+		ClientSecret: "client-secret",
+	}
+
+	for _, expected := range []string{"token-1", "token-2"} {
+		token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != expected {
+			t.Errorf("expected %q, got %q", expected, token)
+		}
+	}
+
+	grants := server.grants()
+	if len(grants) != 2 {
+		t.Fatalf("expected 2 token requests, got %d", len(grants))
+	}
+	if grants[0].Get("grant_type") != GrantTypePassword {
+		t.Errorf("expected the first token to use the password grant, got %q", grants[0].Get("grant_type"))
+	}
+	if grants[1].Get("grant_type") != GrantTypeRefreshToken || grants[1].Get("refresh_token") != "server-refresh-token" {
+		t.Errorf("expected the expired token to be refreshed, got %q", grants[1].Encode())
+	}
+	if grants[1].Get("password") != "" {
+		t.Error("expected the refresh request not to send the password")
+	}
+}
+
+func TestOAuth2TokenFallsBackWhenRefreshFails(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypePassword,
+		Username:  "user",
+		Password:  "secret",
+	}
+	cachePath := a.tokenCachePath(cacheDirectory)
+
+	err := writeCachedToken(cachePath, &cachedToken{
+		AccessToken:  "expired",
+		RefreshToken: "revoked",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The refresh token was revoked, so the password grant is used instead.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") == GrantTypeRefreshToken {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		}
+		server.handle(w, r)
+	})
+
+	token, err := a.OAuth2Token(server.Client(), cacheDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-1" {
+		t.Errorf("expected a new token, got %q", token)
+	}
+
+	cached, err := readCachedToken(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.RefreshToken != "revoked" {
+		t.Errorf("expected the refresh token to be kept, got %q", cached.RefreshToken)
+	}
+}
+
+func TestCachedOAuth2Token(t *testing.T) {
+	server := newTokenServer(t, 3600, "")
+	cacheDirectory := t.TempDir()
+	a := &Auth{
+		Type:      TypeOAuth2,
+		TokenURL:  server.URL,
+		ClientID:  "client",
+		GrantType: GrantTypeClientCredentials,
+	}
+
+	if token, ok := a.CachedOAuth2Token(cacheDirectory); ok {
+		t.Errorf("expected no cached token, got %q", token)
+	}
+	if len(server.grants()) != 0 {
+		t.Fatal("expected no token request")
+	}
+
+	if _, err := a.OAuth2Token(server.Client(), cacheDirectory); err != nil {
+		t.Fatal(err)
+	}
+	if token, ok := a.CachedOAuth2Token(cacheDirectory); !ok || token != "token-1" {
+		t.Errorf("expected the cached token, got %q", token)
+	}
+
+	err := writeCachedToken(a.tokenCachePath(cacheDirectory), &cachedToken{
+		AccessToken: "expired",
+		ExpiresAt:   time.Now().Add(expiryMargin - time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, ok := a.CachedOAuth2Token(cacheDirectory); ok {
+		t.Errorf("expected the expired token not to be returned, got %q", token)
+	}
+	if len(server.grants()) != 1 {
+		t.Errorf("expected 1 token request, got %d", len(server.grants()))
+	}
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/signing"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4DateFormat = "20060102T150405Z"
+	sigV4S3Service  = "s3"
+)
+
+// signV4 signs the request with AWS Signature Version 4. The body is read to
+// compute its hash, and replaced by a copy of it so it can still be sent.
+func (a *Auth) signV4(req *http.Request, now time.Time) error {
+	payload, err := signing.ReadBody(req)
+	if err != nil {
+		return err
+	}
+	payloadHash := sha256Hex(payload)
+
+	now = now.UTC()
+	amzDate := now.Format(sigV4DateFormat)
+	scope := strings.Join([]string{now.Format("20060102"), a.Region, a.Service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	if a.Service == sigV4S3Service {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := sigV4Headers(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4Path(req, a.Service != sigV4S3Service),
+		sigV4Query(req),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + a.SecretKey)
+	for _, part := range []string{now.Format("20060102"), a.Region, a.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, a.AccessKey, scope, signedHeaders, signature,
+	))
+	return nil
+}
+
+// sigV4Headers returns the canonical headers and the list of signed headers.
+// Every header of the request is signed, except the ones which proxies may
+// change.
+func sigV4Headers(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "authorization" || name == "user-agent" || name == "expect" {
+			continue
+		}
+
+		trimmed := make([]string, 0, len(values))
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		value := strings.Join(trimmed, ",")
+		if value == "" {
+			continue
+		}
+		headers[name] = value
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, headers[name])
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// sigV4Path encodes every segment of the path, and makes the request use the
+// same encoding. Services other than S3 expect the segments to be encoded
+// twice.
+func sigV4Path(req *http.Request, encodeTwice bool) string {
+	if req.URL.Path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Encode(segment)
+	}
+	req.URL.RawPath = strings.Join(segments, "/")
+
+	if encodeTwice {
+		for i, segment := range segments {
+			segments[i] = sigV4Encode(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// sigV4Query returns the canonical query, and makes the request send it as is,
+// e.g. with spaces encoded as %20 instead of +.
+func sigV4Query(req *http.Request) string {
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Encode(key)+"="+sigV4Encode(value))
+		}
+	}
+	sort.Strings(pairs)
+	req.URL.RawQuery = strings.Join(pairs, "&")
+	return req.URL.RawQuery
+}
+
+// sigV4Encode encodes everything but the unreserved characters of RFC 3986.
+func sigV4Encode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%%%02X", b)
+	}
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
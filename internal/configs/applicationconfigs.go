@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/retry"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +21,22 @@ type ApplicationConfigs struct {
 	AlwaysEditMethod        bool   `yaml:"alwaysEditMethod"`
 	AlwaysEditContentType   bool   `yaml:"alwaysEditContentType"`
 	AlwaysEditAll           bool   `yaml:"alwaysEditAll"`
+	ActiveEnvironment       string `yaml:"activeEnvironment"`
+	// TLS are the TLS settings of every request, e.g. a custom CA.
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+	// Proxy is the HTTP, HTTPS or SOCKS5 proxy of every request, except the
+	// ones to the hosts of NoProxy.
+	Proxy   string   `yaml:"proxy,omitempty"`
+	NoProxy []string `yaml:"noProxy,omitempty"`
+	// Timeout, ConnectTimeout and MaxRedirects apply to every request which
+	// doesn't set its own, and so does Retry.
+	Timeout        string        `yaml:"timeout,omitempty"`
+	ConnectTimeout string        `yaml:"connectTimeout,omitempty"`
+	MaxRedirects   *int          `yaml:"maxRedirects,omitempty"`
+	Retry          *retry.Policy `yaml:"retry,omitempty"`
+	// Theme is the colour theme of the responses: default, light,
+	// monochrome or none.
+	Theme string `yaml:"theme,omitempty"`
 }
 
 func CreateDefaultConfigs(configFilePath, collectionsDirectoryPath, tmpFilesPath string) {
@@ -0,0 +1,98 @@
+package configs
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/retry"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultConnectTimeout = 30 * time.Second
+	// defaultMaxRedirects is the same limit as the one of the Go client.
+	defaultMaxRedirects = 10
+	// tokenRequestTimeout is the timeout of the oauth2 token requests.
+	tokenRequestTimeout = 30 * time.Second
+)
+
+// clientSettings are the timeouts, redirects and retries of the client, with
+// the ones of the request overriding the ones of the application configs.
+type clientSettings struct {
+	timeout        time.Duration
+	connectTimeout time.Duration
+	maxRedirects   int
+	retry          *retry.Policy
+}
+
+func (config *RequestConfig) clientSettings() (*clientSettings, error) {
+	settings := &clientSettings{maxRedirects: defaultMaxRedirects}
+
+	var err error
+	settings.timeout, err = parseTimeout("timeout", config.Timeout, viper.GetString("timeout"), 0)
+	if err != nil {
+		return nil, err
+	}
+	settings.connectTimeout, err = parseTimeout("connect_timeout", config.ConnectTimeout, viper.GetString("connectTimeout"), defaultConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case config.MaxRedirects != nil:
+		settings.maxRedirects = *config.MaxRedirects
+	case viper.IsSet("maxRedirects"):
+		settings.maxRedirects = viper.GetInt("maxRedirects")
+	}
+	if settings.maxRedirects < 0 {
+		return nil, fmt.Errorf("max redirects can't be negative")
+	}
+
+	settings.retry = config.Retry
+	if settings.retry == nil && viper.IsSet("retry") {
+		settings.retry = &retry.Policy{}
+		if err := viper.UnmarshalKey("retry", settings.retry); err != nil {
+			return nil, fmt.Errorf("invalid retry config: %w", err)
+		}
+	}
+	if err := settings.retry.Validate(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// parseTimeout parses the timeout of the request or, if it's not set, the
+// one of the application configs.
+func parseTimeout(name, requestValue, applicationValue string, defaultValue time.Duration) (time.Duration, error) {
+	value := requestValue
+	if value == "" {
+		value = applicationValue
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid %s %q, it must be like 500ms or 30s", name, value)
+	}
+	return timeout, nil
+}
+
+// checkRedirect follows up to maxRedirects redirects. Once they're reached,
+// the last redirect response is returned, as if redirects weren't followed.
+func checkRedirect(maxRedirects int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+func printRetry(attempt int, wait time.Duration, reason string) {
+	fmt.Fprintf(os.Stderr, "Request failed (%s), retrying in %s (retry %d)...\n", reason, wait, attempt)
+}
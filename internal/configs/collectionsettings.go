@@ -0,0 +1,50 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joaocgduarte/httpmate/internal/variables"
+	"github.com/spf13/cobra"
+)
+
+const collectionSettingsFileName = ".settings.json"
+
+// CollectionSettings are the settings shared by every request of a
+// collection, stored in its .settings.json file. The settings of a
+// sub-collection override the ones of its parent collections.
+type CollectionSettings struct {
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// LoadCollectionSettings merges the settings of the collections directory and
+// of every collection down to collectionPath. Relative certificate paths are
+// relative to the collection of their settings file.
+func LoadCollectionSettings(collectionsPath, collectionPath string) *CollectionSettings {
+	result := &CollectionSettings{}
+	for _, directory := range variables.CollectionDirectories(collectionsPath, collectionPath) {
+		settings, err := readCollectionSettings(filepath.Join(directory, collectionSettingsFileName))
+		if os.IsNotExist(err) {
+			continue
+		}
+		cobra.CheckErr(err)
+
+		result.TLS = result.TLS.merge(settings.TLS.relativeTo(directory))
+	}
+	return result
+}
+
+func readCollectionSettings(path string) (*CollectionSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings CollectionSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid collection settings file %s: %w", path, err)
+	}
+	return &settings, nil
+}
@@ -0,0 +1,48 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCollectionSettingsResolvesRelativePaths(t *testing.T) {
+	collections := t.TempDir()
+	collection := filepath.Join(collections, "payments", "internal")
+	if err := os.MkdirAll(collection, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSettings := func(directory, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(directory, collectionSettingsFileName), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSettings(filepath.Join(collections, "payments"), `{"tls": {"ca_cert": "certs/ca.pem", "client_cert": "/etc/client.pem"}}`)
+	writeSettings(collection, `{"tls": {"client_cert": "client.pem", "client_key": "../keys/client.key"}}`)
+
+	settings := LoadCollectionSettings(collections, collection)
+
+	expected := TLSConfig{
+		CACert:     filepath.Join(collections, "payments", "certs", "ca.pem"),
+		ClientCert: filepath.Join(collection, "client.pem"),
+		ClientKey:  filepath.Join(collections, "payments", "keys", "client.key"),
+	}
+	if settings.TLS == nil || *settings.TLS != expected {
+		t.Errorf("expected %+v, got %+v", expected, settings.TLS)
+	}
+}
+
+func TestLoadCollectionSettingsKeepsAbsolutePaths(t *testing.T) {
+	collections := t.TempDir()
+	content := `{"tls": {"ca_cert": "/etc/ssl/ca.pem"}}`
+	if err := os.WriteFile(filepath.Join(collections, collectionSettingsFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := LoadCollectionSettings(collections, collections)
+
+	if settings.TLS == nil || settings.TLS.CACert != "/etc/ssl/ca.pem" {
+		t.Errorf("expected the absolute path to be kept, got %+v", settings.TLS)
+	}
+}
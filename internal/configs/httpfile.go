@@ -0,0 +1,387 @@
+package configs
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/httpfile"
+	"github.com/joaocgduarte/httpmate/internal/variables"
+)
+
+const multipartBoundary = "httpmate-boundary"
+
+var placeholderRegex = regexp.MustCompile(`{{[^{}]*}}`)
+
+// findHTTPFileRequest loads a request of a .http file, from the path it's
+// listed with (without extension): either the path of a .http file with a
+// single request, or "file/request".
+func findHTTPFileRequest(requestPath string) (*RequestConfig, bool, error) {
+	if reqConfig, found, err := loadHTTPFileRequest(requestPath+httpfile.Extension, ""); found || err != nil {
+		return reqConfig, found, err
+	}
+
+	return loadHTTPFileRequest(filepath.Dir(requestPath)+httpfile.Extension, filepath.Base(requestPath))
+}
+
+// loadHTTPFileRequest loads the request with the given name of a .http file.
+// The name can be empty for files with a single request.
+func loadHTTPFileRequest(path, name string) (*RequestConfig, bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	file, err := httpfile.ParseFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if name == "" {
+		if len(file.Requests) != 1 {
+			return nil, false, nil
+		}
+		name = file.Requests[0].Name
+	}
+
+	request, found := file.Find(name)
+	if !found {
+		return nil, false, nil
+	}
+
+	reqConfig := newRequestConfigFromHTTPFileRequest(path, request)
+	if len(file.Requests) == 1 {
+		reqConfig.Collection = filepath.Dir(path)
+		reqConfig.RequestName = strings.TrimSuffix(filepath.Base(path), httpfile.Extension)
+	}
+
+	return reqConfig.ResolveVariables(resolveFileVariables(file.Variables)), true, nil
+}
+
+// resolveFileVariables resolves the file variables which reference other file
+// variables. The ones which reference environment variables are kept as
+// placeholders, to be resolved when the request is run.
+func resolveFileVariables(vars map[string]string) map[string]string {
+	resolved := make(map[string]string, len(vars))
+	for key, value := range vars {
+		resolved[key] = value
+	}
+
+	for i := 0; i < len(resolved); i++ {
+		for key, value := range resolved {
+			resolved[key] = variables.Interpolate(value, resolved)
+		}
+	}
+	return resolved
+}
+
+// newRequestConfigFromHTTPFileRequest converts a request of a .http file. The
+// Collection of the request is the path of the file without extension, so
+// that it's listed as "file/request".
+func newRequestConfigFromHTTPFileRequest(path string, request *httpfile.Request) *RequestConfig {
+	reqConfig := &RequestConfig{
+		Collection:  strings.TrimSuffix(path, httpfile.Extension),
+		RequestName: request.Name,
+		Method:      request.Method,
+		QueryParams: map[string]string{},
+		Headers:     map[string]string{},
+		HTTPFile:    path,
+	}
+
+	var query string
+	reqConfig.Domain, reqConfig.Path, query = splitHTTPFileURL(request.URL)
+	values, _ := url.ParseQuery(query)
+	for key := range values {
+		reqConfig.QueryParams[key] = values.Get(key)
+	}
+
+	for _, header := range request.Headers {
+		switch {
+		case strings.EqualFold(header.Name, "Content-Type"):
+			reqConfig.ContentType = header.Value
+		case strings.EqualFold(header.Name, "Host") && reqConfig.Domain == "":
+			reqConfig.Domain = fmt.Sprintf("http://%s", header.Value)
+		case strings.EqualFold(header.Name, "Authorization") && parseHTTPFileAuth(header.Value) != nil:
+			reqConfig.Auth = parseHTTPFileAuth(header.Value)
+		default:
+			reqConfig.Headers[header.Name] = header.Value
+		}
+	}
+
+	if request.BodyFile != "" {
+		bodyFile := request.BodyFile
+		if !filepath.IsAbs(bodyFile) && !strings.HasPrefix(bodyFile, "{{") {
+			bodyFile = filepath.Join(filepath.Dir(path), bodyFile)
+		}
+		reqConfig.Body.BinaryFileBody = &bodyFile
+		return reqConfig
+	}
+
+	if request.Body == "" {
+		return reqConfig
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(reqConfig.ContentType)
+	switch ContentType(mediaType) {
+	case ContentTypeFormURLEncoded:
+		// Form bodies can be split in several lines.
+		values, err := url.ParseQuery(strings.ReplaceAll(request.Body, "\n", ""))
+		if err == nil {
+			reqConfig.Body.FormURLEncoded = map[string]string{}
+			for key := range values {
+				reqConfig.Body.FormURLEncoded[key] = values.Get(key)
+			}
+			return reqConfig
+		}
+	case ContentTypeMultipartFormData:
+		parts, ok := parseHTTPFileMultipartBody(filepath.Dir(path), request.Body, params["boundary"])
+		if ok {
+			reqConfig.ContentType = string(ContentTypeMultipartFormData)
+			reqConfig.Body.MultipartBody = parts
+			return reqConfig
+		}
+	}
+
+	body := request.Body
+	reqConfig.Body.RawBody = &body
+	return reqConfig
+}
+
+// parseHTTPFileAuth parses the credentials of the Authorization headers which
+// the REST clients encode by themselves, i.e. "Basic username:password",
+// "Basic username password" and "Digest username password".
+func parseHTTPFileAuth(value string) *auth.Auth {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	result := &auth.Auth{}
+	switch strings.ToLower(fields[0]) {
+	case "basic":
+		result.Type = auth.TypeBasic
+	case "digest":
+		result.Type = auth.TypeDigest
+	default:
+		return nil
+	}
+
+	credentials := strings.TrimSpace(strings.TrimSpace(value)[len(fields[0]):])
+	switch {
+	case strings.Contains(fields[1], ":"):
+		result.Username, result.Password, _ = strings.Cut(credentials, ":")
+	case len(fields) == 3:
+		result.Username, result.Password = fields[1], fields[2]
+	default:
+		// Already encoded credentials are sent as they are.
+		return nil
+	}
+	return result
+}
+
+// splitHTTPFileURL splits a URL, which can start with a placeholder such as
+// "{{host}}", into its domain, path and query.
+func splitHTTPFileURL(rawURL string) (string, string, string) {
+	rawURL, query, _ := strings.Cut(rawURL, "?")
+
+	start := 0
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		start = i + len("://")
+	} else if strings.HasPrefix(rawURL, "{{") {
+		start = strings.Index(rawURL, "}}") + len("}}")
+	}
+
+	i := strings.Index(rawURL[start:], "/")
+	if i < 0 {
+		return rawURL, "", query
+	}
+	return rawURL[:start+i], rawURL[start+i:], query
+}
+
+// parseHTTPFileMultipartBody parses a multipart body written by hand, in which
+// the files are referenced as "< ./file".
+func parseHTTPFileMultipartBody(directory, body, boundary string) ([]*MultipartBodyConfig, bool) {
+	if boundary == "" {
+		return nil, false
+	}
+
+	parts := make([]*MultipartBodyConfig, 0)
+	for _, rawPart := range strings.Split(body, "--"+boundary) {
+		rawPart = strings.TrimPrefix(rawPart, "\n")
+		if strings.TrimSpace(rawPart) == "" || strings.HasPrefix(rawPart, "--") {
+			continue
+		}
+
+		rawHeaders, content, found := strings.Cut(rawPart, "\n\n")
+		if !found {
+			return nil, false
+		}
+
+		var params map[string]string
+		for _, header := range strings.Split(rawHeaders, "\n") {
+			name, value, _ := strings.Cut(header, ":")
+			if strings.EqualFold(strings.TrimSpace(name), "Content-Disposition") {
+				_, params, _ = mime.ParseMediaType(strings.TrimSpace(value))
+			}
+		}
+		if params["name"] == "" {
+			return nil, false
+		}
+
+		part := &MultipartBodyConfig{Key: params["name"]}
+		content = strings.TrimSuffix(content, "\n")
+		if filePath, isFile := strings.CutPrefix(strings.TrimSpace(content), "< "); isFile {
+			filePath = strings.TrimSpace(filePath)
+			if !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "{{") {
+				filePath = filepath.Join(directory, filePath)
+			}
+			part.BinaryFilePathValue = &filePath
+		} else {
+			part.PlainTextValue = &content
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, len(parts) > 0
+}
+
+// ToHTTPFileRequest converts the request to a request of a .http file, with
+// the given name. The returned warnings list the settings which can't be
+// written in a .http file.
+func (config *RequestConfig) ToHTTPFileRequest(name string) (httpfile.Request, []string) {
+	request := httpfile.Request{
+		Name:    name,
+		Method:  config.Method,
+		URL:     fmt.Sprintf("%s/%s", strings.TrimRight(config.Domain, "/"), strings.TrimLeft(config.Path, "/")),
+		Headers: make([]httpfile.Header, 0),
+	}
+
+	queryParams := config.QueryParams
+	if config.Auth != nil && config.Auth.Type == auth.TypeAPIKey && config.Auth.In == auth.InQuery {
+		queryParams = map[string]string{config.Auth.Key: config.Auth.Value}
+		for key, value := range config.QueryParams {
+			queryParams[key] = value
+		}
+	}
+	if len(queryParams) > 0 {
+		request.URL = fmt.Sprintf("%s?%s", request.URL, encodeHTTPFileValues(queryParams))
+	}
+
+	contentType := config.ContentType
+	switch {
+	case len(config.Body.MultipartBody) > 0:
+		contentType = fmt.Sprintf("%s; boundary=%s", ContentTypeMultipartFormData, multipartBoundary)
+		request.Body = formatHTTPFileMultipartBody(config.Body.MultipartBody)
+	case len(config.Body.FormURLEncoded) > 0:
+		contentType = string(ContentTypeFormURLEncoded)
+		request.Body = encodeHTTPFileValues(config.Body.FormURLEncoded)
+	case config.Body.BinaryFileBody != nil:
+		request.BodyFile = *config.Body.BinaryFileBody
+	case config.Body.RawBody != nil:
+		request.Body = *config.Body.RawBody
+	}
+
+	if contentType != "" {
+		request.Headers = append(request.Headers, httpfile.Header{Name: "Content-Type", Value: contentType})
+	}
+	warnings := make([]string, 0)
+	header, ok, err := httpFileAuthHeader(config.Auth)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if ok {
+		request.Headers = append(request.Headers, header)
+	}
+	for _, key := range sortedKeys(config.Headers) {
+		request.Headers = append(request.Headers, httpfile.Header{Name: key, Value: config.Headers[key]})
+	}
+	if config.HMAC != nil {
+		warnings = append(warnings, "hmac signature was not exported, the request won't be signed")
+	}
+
+	return request, warnings
+}
+
+// httpFileAuthHeader returns the header of the auth, if it can be written in
+// a .http file. The auths which are computed when the request is sent can't,
+// and are returned as an error.
+func httpFileAuthHeader(requestAuth *auth.Auth) (httpfile.Header, bool, error) {
+	if requestAuth == nil {
+		return httpfile.Header{}, false, nil
+	}
+
+	switch requestAuth.Type {
+	case auth.TypeBasic:
+		return httpfile.Header{Name: "Authorization", Value: fmt.Sprintf("Basic %s:%s", requestAuth.Username, requestAuth.Password)}, true, nil
+	case auth.TypeDigest:
+		return httpfile.Header{Name: "Authorization", Value: fmt.Sprintf("Digest %s %s", requestAuth.Username, requestAuth.Password)}, true, nil
+	case auth.TypeBearer:
+		return httpfile.Header{Name: "Authorization", Value: "Bearer " + requestAuth.Token}, true, nil
+	case auth.TypeAPIKey:
+		if requestAuth.In != auth.InQuery {
+			return httpfile.Header{Name: requestAuth.Key, Value: requestAuth.Value}, true, nil
+		}
+	case auth.TypeOAuth2:
+		return httpfile.Header{}, false, fmt.Errorf("oauth2 auth was not exported, set an Authorization header with a token from %s", requestAuth.TokenURL)
+	case auth.TypeAWSSigV4:
+		return httpfile.Header{}, false, fmt.Errorf("aws_sigv4 auth was not exported, the request won't be signed")
+	}
+	return httpfile.Header{}, false, nil
+}
+
+func formatHTTPFileMultipartBody(parts []*MultipartBodyConfig) string {
+	var b strings.Builder
+	for _, part := range parts {
+		fmt.Fprintf(&b, "--%s\n", multipartBoundary)
+		switch {
+		case part.BinaryFilePathValue != nil:
+			fmt.Fprintf(&b, "Content-Disposition: form-data; name=%q; filename=%q\n\n", part.Key, filepath.Base(*part.BinaryFilePathValue))
+			fmt.Fprintf(&b, "< %s\n", *part.BinaryFilePathValue)
+		case part.PlainTextValue != nil:
+			fmt.Fprintf(&b, "Content-Disposition: form-data; name=%q\n\n", part.Key)
+			fmt.Fprintf(&b, "%s\n", *part.PlainTextValue)
+		}
+	}
+	fmt.Fprintf(&b, "--%s--", multipartBoundary)
+	return b.String()
+}
+
+// encodeHTTPFileValues encodes query params or form values, keeping the
+// {{variables}} as they are.
+func encodeHTTPFileValues(values map[string]string) string {
+	escape := func(value string) string {
+		var b strings.Builder
+		last := 0
+		for _, match := range placeholderRegex.FindAllStringIndex(value, -1) {
+			b.WriteString(url.QueryEscape(value[last:match[0]]))
+			b.WriteString(value[match[0]:match[1]])
+			last = match[1]
+		}
+		b.WriteString(url.QueryEscape(value[last:]))
+		return b.String()
+	}
+
+	pairs := make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", escape(key), escape(values[key])))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,264 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/httpfile"
+	"github.com/joaocgduarte/httpmate/internal/signing"
+)
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+// roundTrip writes the request to a .http file and loads it back.
+func roundTrip(t *testing.T, config *RequestConfig) (*RequestConfig, []string) {
+	t.Helper()
+
+	request, warnings := config.ToHTTPFileRequest(config.RequestName)
+	path := filepath.Join(t.TempDir(), "users"+httpfile.Extension)
+	content := httpfile.Format(&httpfile.File{Requests: []httpfile.Request{request}})
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := httpfile.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Requests) != 1 {
+		t.Fatalf("expected 1 request, got %d:\n%s", len(file.Requests), content)
+	}
+	return newRequestConfigFromHTTPFileRequest(path, &file.Requests[0]), warnings
+}
+
+func TestHTTPFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RequestConfig
+	}{
+		{
+			name: "query and headers",
+			config: RequestConfig{
+				Method:      "GET",
+				Domain:      "https://api.example.com",
+				Path:        "/users",
+				QueryParams: map[string]string{"name": "joão d", "page": "{{page}}"},
+				Headers:     map[string]string{"Accept": "application/json", "X-Trace": "1"},
+			},
+		},
+		{
+			name: "raw body",
+			config: RequestConfig{
+				Method:      "POST",
+				Domain:      "{{host}}",
+				Path:        "/users",
+				ContentType: "application/json",
+				Body:        RequestBodyConfig{RawBody: stringPointer("{\n  \"name\": \"httpmate\"\n}")},
+			},
+		},
+		{
+			name: "form body",
+			config: RequestConfig{
+				Method:      "POST",
+				Domain:      "https://api.example.com",
+				Path:        "/login",
+				ContentType: string(ContentTypeFormURLEncoded),
+				Body: RequestBodyConfig{FormURLEncoded: map[string]string{
+					"username": "{{user}}",
+					"password": "a&b=c d",
+				}},
+			},
+		},
+		{
+			name: "multipart body",
+			config: RequestConfig{
+				Method:      "POST",
+				Domain:      "https://api.example.com",
+				Path:        "/upload",
+				ContentType: string(ContentTypeMultipartFormData),
+				Body: RequestBodyConfig{MultipartBody: []*MultipartBodyConfig{
+					{Key: "description", PlainTextValue: stringPointer("two\nlines")},
+					{Key: "file", BinaryFilePathValue: stringPointer("/tmp/avatar.png")},
+					{Key: "other", BinaryFilePathValue: stringPointer("{{directory}}/other.png")},
+				}},
+			},
+		},
+		{
+			name: "file body",
+			config: RequestConfig{
+				Method:      "PUT",
+				Domain:      "https://api.example.com",
+				Path:        "/avatar",
+				ContentType: "image/png",
+				Body:        RequestBodyConfig{BinaryFileBody: stringPointer("/tmp/avatar.png")},
+			},
+		},
+		{
+			name: "basic auth",
+			config: RequestConfig{
+				Method: "GET",
+				Domain: "https://api.example.com",
+				Path:   "/me",
+				Auth:   &auth.Auth{Type: auth.TypeBasic, Username: "joao", Password: "{{password}}"},
+			},
+		},
+		{
+			name: "digest auth",
+			config: RequestConfig{
+				Method: "GET",
+				Domain: "https://api.example.com",
+				Path:   "/me",
+				Auth:   &auth.Auth{Type: auth.TypeDigest, Username: "joao", Password: "secret"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := test.config
+			config.RequestName = "request"
+
+			result, warnings := roundTrip(t, &config)
+
+			if len(warnings) != 0 {
+				t.Errorf("unexpected warnings %v", warnings)
+			}
+			expected := config
+			expected.Collection = result.Collection
+			expected.HTTPFile = result.HTTPFile
+			if expected.QueryParams == nil {
+				expected.QueryParams = map[string]string{}
+			}
+			if expected.Headers == nil {
+				expected.Headers = map[string]string{}
+			}
+			if !reflect.DeepEqual(result, &expected) {
+				t.Errorf("expected\n%+v\ngot\n%+v", expected, *result)
+			}
+		})
+	}
+}
+
+func TestHTTPFileRoundTripRelativePaths(t *testing.T) {
+	config := &RequestConfig{
+		RequestName: "upload",
+		Method:      "POST",
+		Domain:      "https://api.example.com",
+		Path:        "/upload",
+		Body: RequestBodyConfig{MultipartBody: []*MultipartBodyConfig{
+			{Key: "file", BinaryFilePathValue: stringPointer("images/avatar.png")},
+		}},
+	}
+
+	result, _ := roundTrip(t, config)
+
+	// Paths in a .http file are relative to its directory.
+	expected := filepath.Join(filepath.Dir(result.HTTPFile), "images", "avatar.png")
+	parts := result.Body.MultipartBody
+	if len(parts) != 1 || parts[0].BinaryFilePathValue == nil || *parts[0].BinaryFilePathValue != expected {
+		t.Errorf("expected the file %s, got %+v", expected, parts)
+	}
+}
+
+func TestHTTPFileRoundTripAuthHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    *auth.Auth
+		headers map[string]string
+		query   map[string]string
+	}{
+		{
+			name:    "bearer",
+			auth:    &auth.Auth{Type: auth.TypeBearer, Token: "{{token}}"},
+			headers: map[string]string{"Authorization": "Bearer {{token}}"},
+			query:   map[string]string{},
+		},
+		{
+			name:    "api key in a header",
+			auth:    &auth.Auth{Type: auth.TypeAPIKey, Key: "X-API-Key", Value: "key"},
+			headers: map[string]string{"X-API-Key": "key"},
+			query:   map[string]string{},
+		},
+		{
+			name:    "api key in the query",
+			auth:    &auth.Auth{Type: auth.TypeAPIKey, Key: "api_key", Value: "key", In: auth.InQuery},
+			headers: map[string]string{},
+			query:   map[string]string{"api_key": "key", "page": "1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &RequestConfig{
+				RequestName: "request",
+				Method:      "GET",
+				Domain:      "https://api.example.com",
+				Path:        "/users",
+				QueryParams: map[string]string{"page": "1"},
+				Auth:        test.auth,
+			}
+			if test.auth.In != auth.InQuery {
+				test.query["page"] = "1"
+			}
+
+			result, _ := roundTrip(t, config)
+
+			if result.Auth != nil {
+				t.Errorf("expected the auth to be sent as is, got %+v", result.Auth)
+			}
+			if !reflect.DeepEqual(result.Headers, test.headers) {
+				t.Errorf("expected the headers %v, got %v", test.headers, result.Headers)
+			}
+			if !reflect.DeepEqual(result.QueryParams, test.query) {
+				t.Errorf("expected the query %v, got %v", test.query, result.QueryParams)
+			}
+		})
+	}
+}
+
+func TestToHTTPFileRequestWarnings(t *testing.T) {
+	config := &RequestConfig{
+		Method: "GET",
+		Domain: "https://api.example.com",
+		Auth:   &auth.Auth{Type: auth.TypeOAuth2, TokenURL: "https://auth.example.com/token"},
+		HMAC:   &signing.HMAC{Secret: "secret"},
+	}
+
+	request, warnings := config.ToHTTPFileRequest("request")
+
+	expected := []string{
+		"oauth2 auth was not exported, set an Authorization header with a token from https://auth.example.com/token",
+		"hmac signature was not exported, the request won't be signed",
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected the warnings %v, got %v", expected, warnings)
+	}
+	if len(request.Headers) != 0 {
+		t.Errorf("expected no headers, got %v", request.Headers)
+	}
+}
+
+func TestParseHTTPFileAuth(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected *auth.Auth
+	}{
+		{value: "Basic joao:se:cret", expected: &auth.Auth{Type: auth.TypeBasic, Username: "joao", Password: "se:cret"}},
+		{value: "basic joao secret", expected: &auth.Auth{Type: auth.TypeBasic, Username: "joao", Password: "secret"}},
+		{value: "Digest joao secret", expected: &auth.Auth{Type: auth.TypeDigest, Username: "joao", Password: "secret"}},
+		{value: "Basic am9hbzpzZWNyZXQ=", expected: nil},
+		{value: "Bearer token", expected: nil},
+		{value: "Basic", expected: nil},
+	}
+
+	for _, test := range tests {
+		if result := parseHTTPFileAuth(test.value); !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.value, test.expected, result)
+		}
+	}
+}
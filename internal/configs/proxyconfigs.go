@@ -0,0 +1,107 @@
+package configs
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ProxySettings returns the proxy through which the request is sent, and the
+// hosts which are reached directly. The --proxy flag overrides the proxy of
+// the request, which overrides the one of the application configs.
+func (config *RequestConfig) ProxySettings() (string, []string) {
+	proxy := viper.GetString("proxy")
+	if config.Proxy != "" {
+		proxy = config.Proxy
+	}
+	if flagProxy := viper.GetString("proxyFlag"); flagProxy != "" {
+		proxy = flagProxy
+	}
+
+	var noProxy []string
+	for _, entry := range viper.GetStringSlice("noProxy") {
+		for _, host := range strings.Split(entry, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				noProxy = append(noProxy, host)
+			}
+		}
+	}
+	return proxy, noProxy
+}
+
+// proxyFunc returns the proxy selection of the transport. Without a proxy,
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+func proxyFunc(proxy string, noProxy []string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, it must be like http://host:port", proxy)
+	}
+	if !isProxyScheme(proxyURL.Scheme) {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, it must be one of %s", proxyURL.Scheme, strings.Join(proxySchemes, ", "))
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+func isProxyScheme(scheme string) bool {
+	for _, proxyScheme := range proxySchemes {
+		if scheme == proxyScheme {
+			return true
+		}
+	}
+	return false
+}
+
+// bypassProxy checks if the host of the URL is in the no-proxy list, which
+// can contain "*", hosts (matching their subdomains too, with or without a
+// leading dot), IPs and CIDR ranges, optionally with a port.
+func bypassProxy(u *url.URL, noProxy []string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(entry)
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			entryHost, entryPort = entry, ""
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		entryHost = strings.TrimPrefix(strings.Trim(entryHost, "[]"), "*")
+		if host == strings.TrimPrefix(entryHost, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(entryHost, ".")) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,14 +6,25 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/joaocgduarte/httpmate/internal/assertions"
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/cookies"
+	"github.com/joaocgduarte/httpmate/internal/extract"
 	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/joaocgduarte/httpmate/internal/httpfile"
 	"github.com/joaocgduarte/httpmate/internal/prompts"
+	"github.com/joaocgduarte/httpmate/internal/retry"
+	"github.com/joaocgduarte/httpmate/internal/signing"
+	"github.com/joaocgduarte/httpmate/internal/variables"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -34,18 +45,35 @@ type RequestBodyConfig struct {
 }
 
 type RequestConfig struct {
-	Collection  string            `json:"collection"`
-	RequestName string            `json:"request_name"`
-	Domain      string            `json:"domain"`
-	Path        string            `json:"path"`
-	Method      string            `json:"method"`
-	QueryParams map[string]string `json:"query_params"`
-	Headers     map[string]string `json:"headers"`
-	ContentType string            `json:"content_type"`
-	Body        RequestBodyConfig `json:"body"`
+	Collection     string                 `json:"collection"`
+	RequestName    string                 `json:"request_name"`
+	Domain         string                 `json:"domain"`
+	Path           string                 `json:"path"`
+	Method         string                 `json:"method"`
+	QueryParams    map[string]string      `json:"query_params"`
+	Headers        map[string]string      `json:"headers"`
+	ContentType    string                 `json:"content_type"`
+	Body           RequestBodyConfig      `json:"body"`
+	Auth           *auth.Auth             `json:"auth,omitempty"`
+	HMAC           *signing.HMAC          `json:"hmac,omitempty"`
+	TLS            *TLSConfig             `json:"tls,omitempty"`
+	Proxy          string                 `json:"proxy,omitempty"`
+	Timeout        string                 `json:"timeout,omitempty"`
+	ConnectTimeout string                 `json:"connect_timeout,omitempty"`
+	MaxRedirects   *int                   `json:"max_redirects,omitempty"`
+	Retry          *retry.Policy          `json:"retry,omitempty"`
+	Assertions     *assertions.Assertions `json:"assertions,omitempty"`
+	Extract        []extract.Extraction   `json:"extract,omitempty"`
+	// HTTPFile is the .http file from which the request was loaded. These
+	// requests are not written back, since the file is edited by hand.
+	HTTPFile string `json:"-"`
 }
 
 func (r *RequestConfig) WriteToJSONFile() {
+	if r.HTTPFile != "" {
+		return
+	}
+
 	files.WriteStructToJSONFile(
 		r,
 		filepath.Join(r.Collection, fmt.Sprintf("%s.json", r.RequestName)),
@@ -90,6 +118,7 @@ func PromptRequestConfig(collectionsPath string) *RequestConfig {
 		}),
 		QueryParams: prompts.PromptWhileConfirm("Do you want to add a query parameter?", "Query parameter key", "Query parameter value"),
 		Headers:     prompts.PromptWhileConfirm("Do you want to add a header?", "Header key (don't add Content-Type)", "Header value"),
+		Auth:        promptAuth(),
 	}
 
 	config.ContentType = prompts.SelectWithAdd("Content-Type", "Other", []string{
@@ -124,6 +153,12 @@ func PromptRequestConfig(collectionsPath string) *RequestConfig {
 	return config
 }
 
+// PromptCollection prompts the user to choose one of the collections, or to
+// create a new one, and returns its path.
+func PromptCollection(collectionsPath string) string {
+	return chooseCollection(collectionsPath, files.GetSubDirectories(collectionsPath))
+}
+
 func chooseCollection(collectionsPath string, collections []string) string {
 	result := prompts.SelectWithAdd(
 		"Choose one of your collections",
@@ -136,6 +171,54 @@ func chooseCollection(collectionsPath string, collections []string) string {
 	return collectionPath
 }
 
+func promptAuth() *auth.Auth {
+	options := []string{"none"}
+	for _, authType := range auth.Types {
+		options = append(options, string(authType))
+	}
+
+	result := &auth.Auth{Type: auth.Type(prompts.Select("Authentication", options))}
+	switch result.Type {
+	case auth.TypeBasic, auth.TypeDigest:
+		result.Username = prompts.Prompt("Username")
+		result.Password = prompts.Prompt("Password")
+	case auth.TypeBearer:
+		result.Token = prompts.Prompt("Token")
+	case auth.TypeAPIKey:
+		result.Key = prompts.Prompt("API key name (e.g. X-API-Key)")
+		result.Value = prompts.Prompt("API key value")
+		result.In = prompts.Select("Send the API key in", []string{auth.InHeader, auth.InQuery})
+	case auth.TypeOAuth2:
+		result.TokenURL = prompts.Prompt("Token URL")
+		result.ClientID = prompts.Prompt("Client ID")
+		result.ClientSecret = prompts.Prompt("Client secret")
+		result.GrantType = prompts.Select("Grant type", []string{
+			auth.GrantTypeClientCredentials,
+			auth.GrantTypePassword,
+			auth.GrantTypeRefreshToken,
+		})
+		switch result.GrantType {
+		case auth.GrantTypePassword:
+			result.Username = prompts.Prompt("Username")
+			result.Password = prompts.Prompt("Password")
+		case auth.GrantTypeRefreshToken:
+			result.RefreshToken = prompts.Prompt("Refresh token")
+		}
+		if scopes := prompts.Prompt("Scopes (separated by spaces)"); scopes != "" {
+			result.Scopes = strings.Fields(scopes)
+		}
+	case auth.TypeAWSSigV4:
+		result.AccessKey = prompts.Prompt("Access key ID")
+		result.SecretKey = prompts.Prompt("Secret access key")
+		result.SessionToken = prompts.Prompt("Session token (optional)")
+		result.Region = prompts.Prompt("Region (e.g. us-east-1)")
+		result.Service = prompts.Prompt("Service (e.g. execute-api)")
+	default:
+		return nil
+	}
+	return result
+}
+
 func promptRawBody(contentType ContentType) *string {
 	if contentType == ContentTypeMultipartFormData ||
 		contentType == ContentTypeOctetStream ||
@@ -393,16 +476,72 @@ func (config *RequestConfig) editAll() RequestConfig {
 	err = json.Unmarshal([]byte(alteredConfigs), &newConfigs)
 	cobra.CheckErr(err)
 
-	files.WriteStructToJSONFile(
-		newConfigs,
-		filepath.Join(newConfigs.Collection, fmt.Sprintf("%s.json", newConfigs.RequestName)),
-	)
+	newConfigs.HTTPFile = config.HTTPFile
+	newConfigs.WriteToJSONFile()
 	return newConfigs
 }
 
-// ConvertToCurlCommand converts RequestConfig to a curl command string.
-func (config *RequestConfig) ConvertToCurlCommand() string {
+// ResolveVariables returns a copy of the RequestConfig with every {{variable}}
+// placeholder replaced by its value. The original config is left untouched, so
+// it can still be written back to its file with the placeholders.
+func (config *RequestConfig) ResolveVariables(vars map[string]string) *RequestConfig {
+	resolve := func(value string) string {
+		return variables.Interpolate(value, vars)
+	}
+	resolvePointer := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		resolved := resolve(*value)
+		return &resolved
+	}
+	resolveMap := func(values map[string]string) map[string]string {
+		if values == nil {
+			return nil
+		}
+		resolved := make(map[string]string, len(values))
+		for key, value := range values {
+			resolved[resolve(key)] = resolve(value)
+		}
+		return resolved
+	}
+
+	resolved := *config
+	resolved.Domain = resolve(config.Domain)
+	resolved.Path = resolve(config.Path)
+	resolved.ContentType = resolve(config.ContentType)
+	resolved.QueryParams = resolveMap(config.QueryParams)
+	resolved.Headers = resolveMap(config.Headers)
+	resolved.Auth = config.Auth.Resolve(resolve)
+	resolved.HMAC = config.HMAC.Resolve(resolve)
+	resolved.TLS = config.TLS.resolve(resolve)
+	resolved.Proxy = resolve(config.Proxy)
+
+	resolved.Body = RequestBodyConfig{
+		RawBody:        resolvePointer(config.Body.RawBody),
+		BinaryFileBody: resolvePointer(config.Body.BinaryFileBody),
+		FormURLEncoded: resolveMap(config.Body.FormURLEncoded),
+	}
+	if config.Body.MultipartBody != nil {
+		resolved.Body.MultipartBody = make([]*MultipartBodyConfig, 0, len(config.Body.MultipartBody))
+		for _, part := range config.Body.MultipartBody {
+			resolved.Body.MultipartBody = append(resolved.Body.MultipartBody, &MultipartBodyConfig{
+				Key:                 resolve(part.Key),
+				PlainTextValue:      resolvePointer(part.PlainTextValue),
+				BinaryFilePathValue: resolvePointer(part.BinaryFilePathValue),
+			})
+		}
+	}
+
+	return &resolved
+}
+
+// ConvertToCurlCommand converts RequestConfig to a curl command string, with
+// the signature headers of req, the request which is sent. What curl can't do
+// the same way is explained by "#" comments before the command.
+func (config *RequestConfig) ConvertToCurlCommand(req *http.Request) string {
 	var curlCmd strings.Builder
+	var notes []string
 
 	// Append curl command basics
 	curlCmd.WriteString("curl -X ")
@@ -410,17 +549,138 @@ func (config *RequestConfig) ConvertToCurlCommand() string {
 	curlCmd.WriteString(" '")
 	curlCmd.WriteString(config.Domain)
 	curlCmd.WriteString(config.Path)
-	curlCmd.WriteString("'")
 
 	// Append query parameters
-	if len(config.QueryParams) > 0 {
+	queryParams := url.Values{}
+	for key, value := range config.QueryParams {
+		queryParams.Set(key, value)
+	}
+	if config.Auth != nil && config.Auth.Type == auth.TypeAPIKey && config.Auth.In == auth.InQuery {
+		queryParams.Set(config.Auth.Key, config.Auth.Value)
+	}
+	if len(queryParams) > 0 {
 		curlCmd.WriteString("?")
-		queryParams := url.Values{}
-		for key, value := range config.QueryParams {
-			queryParams.Set(key, value)
-		}
 		curlCmd.WriteString(queryParams.Encode())
 	}
+	curlCmd.WriteString("'")
+
+	if config.Auth != nil {
+		switch config.Auth.Type {
+		case auth.TypeBasic, auth.TypeDigest:
+			if config.Auth.Type == auth.TypeDigest {
+				curlCmd.WriteString(" --digest")
+			}
+			curlCmd.WriteString(" -u '")
+			curlCmd.WriteString(config.Auth.Username)
+			curlCmd.WriteString(":")
+			curlCmd.WriteString(config.Auth.Password)
+			curlCmd.WriteString("'")
+		case auth.TypeBearer:
+			curlCmd.WriteString(" -H 'Authorization: Bearer ")
+			curlCmd.WriteString(config.Auth.Token)
+			curlCmd.WriteString("'")
+		case auth.TypeOAuth2:
+			// Tokens aren't requested only to print the command
+			token, ok := config.Auth.CachedOAuth2Token(viper.GetString("temporaryFilesDirectory"))
+			if !ok {
+				token = "{{oauth2_token}}"
+				notes = append(notes, "There's no cached oauth2 token, replace {{oauth2_token}} with an access token from "+config.Auth.TokenURL)
+			}
+			curlCmd.WriteString(" -H 'Authorization: Bearer ")
+			curlCmd.WriteString(token)
+			curlCmd.WriteString("'")
+		case auth.TypeAPIKey:
+			if config.Auth.In != auth.InQuery {
+				curlCmd.WriteString(" -H '")
+				curlCmd.WriteString(config.Auth.Key)
+				curlCmd.WriteString(": ")
+				curlCmd.WriteString(config.Auth.Value)
+				curlCmd.WriteString("'")
+			}
+		case auth.TypeAWSSigV4:
+			curlCmd.WriteString(" --aws-sigv4 'aws:amz:")
+			curlCmd.WriteString(config.Auth.Region)
+			curlCmd.WriteString(":")
+			curlCmd.WriteString(config.Auth.Service)
+			curlCmd.WriteString("' -u '")
+			curlCmd.WriteString(config.Auth.AccessKey)
+			curlCmd.WriteString(":")
+			curlCmd.WriteString(config.Auth.SecretKey)
+			curlCmd.WriteString("'")
+			if config.Auth.SessionToken != "" {
+				curlCmd.WriteString(" -H 'X-Amz-Security-Token: ")
+				curlCmd.WriteString(config.Auth.SessionToken)
+				curlCmd.WriteString("'")
+			}
+		}
+	}
+
+	// curl reads and updates the same cookie jar, once it has cookies
+	if _, err := os.Stat(config.CookieJarPath()); err == nil {
+		curlCmd.WriteString(" -b '")
+		curlCmd.WriteString(config.CookieJarPath())
+		curlCmd.WriteString("' -c '")
+		curlCmd.WriteString(config.CookieJarPath())
+		curlCmd.WriteString("'")
+	}
+
+	settings, err := config.clientSettings()
+	cobra.CheckErr(err)
+	if settings.maxRedirects > 0 {
+		curlCmd.WriteString(" -L")
+	}
+	if settings.maxRedirects > 0 && settings.maxRedirects != defaultMaxRedirects {
+		curlCmd.WriteString(" --max-redirs ")
+		curlCmd.WriteString(strconv.Itoa(settings.maxRedirects))
+	}
+	if settings.connectTimeout != defaultConnectTimeout {
+		curlCmd.WriteString(" --connect-timeout ")
+		curlCmd.WriteString(strconv.FormatFloat(settings.connectTimeout.Seconds(), 'f', -1, 64))
+	}
+	if settings.timeout > 0 {
+		curlCmd.WriteString(" --max-time ")
+		curlCmd.WriteString(strconv.FormatFloat(settings.timeout.Seconds(), 'f', -1, 64))
+	}
+	if settings.retry != nil && settings.retry.Attempts > 0 {
+		curlCmd.WriteString(" --retry ")
+		curlCmd.WriteString(strconv.Itoa(settings.retry.Attempts))
+	}
+
+	if proxy, noProxy := config.ProxySettings(); proxy != "" {
+		curlCmd.WriteString(" -x '")
+		curlCmd.WriteString(proxy)
+		curlCmd.WriteString("'")
+		if len(noProxy) > 0 {
+			curlCmd.WriteString(" --noproxy '")
+			curlCmd.WriteString(strings.Join(noProxy, ","))
+			curlCmd.WriteString("'")
+		}
+	}
+
+	// curl has no option to only change the server name of the handshake
+	tlsSettings := config.TLSSettings()
+	if tlsSettings.CACert != "" {
+		curlCmd.WriteString(" --cacert '")
+		curlCmd.WriteString(tlsSettings.CACert)
+		curlCmd.WriteString("'")
+	}
+	if tlsSettings.ClientCert != "" {
+		curlCmd.WriteString(" --cert '")
+		curlCmd.WriteString(tlsSettings.ClientCert)
+		curlCmd.WriteString("'")
+	}
+	if tlsSettings.ClientKey != "" {
+		curlCmd.WriteString(" --key '")
+		curlCmd.WriteString(tlsSettings.ClientKey)
+		curlCmd.WriteString("'")
+	}
+	if tlsSettings.MinVersion != "" {
+		curlCmd.WriteString(" --tlsv")
+		curlCmd.WriteString(tlsSettings.MinVersion)
+	}
+	if tlsSettings.Insecure {
+		curlCmd.WriteString(" -k")
+	}
 
 	if config.ContentType != "" {
 		curlCmd.WriteString(" -H '")
@@ -438,6 +698,18 @@ func (config *RequestConfig) ConvertToCurlCommand() string {
 		curlCmd.WriteString("'")
 	}
 
+	if config.HMAC != nil && len(config.Body.MultipartBody) > 0 {
+		// curl picks another boundary, so the signed body isn't the same
+		notes = append(notes, "The "+config.HMAC.HeaderName()+" signature must be computed when the request is sent, since curl builds another multipart body")
+	} else {
+		// The signature is only valid for the current time
+		for _, header := range config.hmacHeaders(req) {
+			curlCmd.WriteString(" -H '")
+			curlCmd.WriteString(header)
+			curlCmd.WriteString("'")
+		}
+	}
+
 	// Append request body if present
 	switch {
 	case config.Body.RawBody != nil:
@@ -475,60 +747,221 @@ func (config *RequestConfig) ConvertToCurlCommand() string {
 		curlCmd.WriteString("'")
 	}
 
-	return curlCmd.String()
+	var command strings.Builder
+	for _, note := range notes {
+		command.WriteString("# ")
+		command.WriteString(note)
+		command.WriteString("\n")
+	}
+	command.WriteString(curlCmd.String())
+	return command.String()
 }
 
 func (config *RequestConfig) BuildHTTPRequest() *http.Request {
-	u := config.buildURL()
-	req := config.buildRequest(u)
+	req, err := config.HTTPRequest()
+	cobra.CheckErr(err)
+	return req
+}
+
+// HTTPRequest is the same as BuildHTTPRequest, but it returns the errors of
+// invalid requests (e.g. a missing body file) instead of exiting.
+func (config *RequestConfig) HTTPRequest() (*http.Request, error) {
+	if err := config.Auth.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.HMAC.Validate(); err != nil {
+		return nil, err
+	}
+	if config.HMAC != nil && config.Auth != nil && config.Auth.Type == auth.TypeAWSSigV4 {
+		return nil, fmt.Errorf("hmac signature can't be used with aws_sigv4 auth, since both sign the whole request")
+	}
+
+	u, err := config.buildURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := config.buildRequest(u)
+	if err != nil {
+		return nil, err
+	}
 
 	for key, value := range config.Headers {
 		req.Header.Set(key, value)
 	}
-	return req
+	var tokenClient *http.Client
+	if config.Auth != nil && config.Auth.Type == auth.TypeOAuth2 {
+		tokenClient, err = config.tokenClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := config.Auth.Apply(req, tokenClient, viper.GetString("temporaryFilesDirectory")); err != nil {
+		return nil, err
+	}
+	// The signature is computed last, so it covers the request exactly as
+	// it's sent.
+	if err := config.HMAC.Sign(req, time.Now()); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// hmacHeaders returns the headers which the HMAC signer added to the request,
+// as "Name: value".
+func (config *RequestConfig) hmacHeaders(req *http.Request) []string {
+	if config.HMAC == nil {
+		return nil
+	}
+
+	var headers []string
+	if config.HMAC.TimestampHeader != "" {
+		headers = append(headers, config.HMAC.TimestampHeader+": "+req.Header.Get(config.HMAC.TimestampHeader))
+	}
+	return append(headers, config.HMAC.HeaderName()+": "+req.Header.Get(config.HMAC.HeaderName()))
+}
+
+// NewHTTPClient creates the client with which the request is performed.
+func (config *RequestConfig) NewHTTPClient() *http.Client {
+	client, err := config.HTTPClient()
+	cobra.CheckErr(err)
+	return client
+}
+
+// HTTPClient is the same as NewHTTPClient, but it returns the errors of
+// invalid settings (e.g. a timeout) instead of exiting.
+func (config *RequestConfig) HTTPClient() (*http.Client, error) {
+	settings, err := config.clientSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := config.transport(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookies.Load(config.CookieJarPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport:     settings.retry.Transport(config.Auth.Transport(transport), printRetry),
+		Timeout:       settings.timeout,
+		CheckRedirect: checkRedirect(settings.maxRedirects),
+		Jar:           jar,
+	}, nil
+}
+
+// tokenClient creates the client with which oauth2 tokens are requested. It
+// uses the TLS and proxy settings of the request, but not its retries,
+// redirects, cookies or digest authentication.
+func (config *RequestConfig) tokenClient() (*http.Client, error) {
+	settings, err := config.clientSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := config.transport(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: tokenRequestTimeout}, nil
+}
+
+// transport creates the transport of the request, with its TLS, proxy and
+// connection settings.
+func (config *RequestConfig) transport(settings *clientSettings) (*http.Transport, error) {
+	tlsConfig, err := config.TLSSettings().build()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := proxyFunc(config.ProxySettings())
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	transport.DialContext = (&net.Dialer{
+		Timeout:   settings.connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	return transport, nil
+}
+
+// CookieJarPath returns the cookie jar of the collection of the request. The
+// requests of a .http file share the jar of its directory.
+func (config *RequestConfig) CookieJarPath() string {
+	if config.HTTPFile != "" {
+		return filepath.Join(filepath.Dir(config.HTTPFile), cookies.FileName)
+	}
+	return filepath.Join(config.Collection, cookies.FileName)
 }
 
-func (config *RequestConfig) buildURL() *url.URL {
+// TLSSettings returns the TLS settings of the request, merged over the ones
+// of its collections and of the application.
+func (config *RequestConfig) TLSSettings() *TLSConfig {
+	collection := LoadCollectionSettings(viper.GetString("collectionDirectory"), config.Collection)
+	return applicationTLSConfig().merge(collection.TLS).merge(config.TLS)
+}
+
+func (config *RequestConfig) buildURL() (*url.URL, error) {
 	domain := strings.Trim(config.Domain, "/")
 	path := strings.Trim(config.Path, "/")
 	baseURL := fmt.Sprintf("%s/%s", domain, path)
 
 	u, err := url.Parse(baseURL)
-	cobra.CheckErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	q := u.Query()
 	for key, value := range config.QueryParams {
 		q.Set(strings.Trim(key, " "), strings.Trim(value, " "))
 	}
 	u.RawQuery = q.Encode()
-	return u
+	return u, nil
 }
 
-func (config *RequestConfig) buildRequest(u *url.URL) *http.Request {
+func (config *RequestConfig) buildRequest(u *url.URL) (*http.Request, error) {
 	if config.Body.RawBody != nil {
 		req, err := http.NewRequest(config.Method, u.String(), strings.NewReader(*config.Body.RawBody))
-		cobra.CheckErr(err)
+		if err != nil {
+			return nil, err
+		}
 		setRequestHeaderFromConfig(req, config)
-		return req
+		return req, nil
 	}
 
 	if config.Body.BinaryFileBody != nil {
 		file, err := os.Open(*config.Body.BinaryFileBody)
-		cobra.CheckErr(err)
+		if err != nil {
+			return nil, err
+		}
 		req, err := http.NewRequest(config.Method, u.String(), file)
-		cobra.CheckErr(err)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
 		setRequestHeaderFromConfig(req, config)
-		return req
+		return req, nil
 	}
 
 	if len(config.Body.MultipartBody) > 0 {
 		body, contentType, err := createMultipartBody(config.Body.MultipartBody)
-		cobra.CheckErr(err)
+		if err != nil {
+			return nil, err
+		}
 
 		req, err := http.NewRequest(config.Method, u.String(), body)
-		cobra.CheckErr(err)
+		if err != nil {
+			return nil, err
+		}
 		req.Header.Set("Content-Type", contentType)
-		return req
+		return req, nil
 	}
 
 	if len(config.Body.FormURLEncoded) > 0 {
@@ -537,15 +970,19 @@ func (config *RequestConfig) buildRequest(u *url.URL) *http.Request {
 			data.Set(key, value)
 		}
 		req, err := http.NewRequest(config.Method, u.String(), strings.NewReader(data.Encode()))
-		cobra.CheckErr(err)
+		if err != nil {
+			return nil, err
+		}
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		return req
+		return req, nil
 	}
 
 	req, err := http.NewRequest(config.Method, u.String(), nil)
-	cobra.CheckErr(err)
+	if err != nil {
+		return nil, err
+	}
 	setRequestHeaderFromConfig(req, config)
-	return req
+	return req, nil
 }
 
 func createMultipartBody(bodyConfig []*MultipartBodyConfig) (io.Reader, string, error) {
@@ -555,30 +992,44 @@ func createMultipartBody(bodyConfig []*MultipartBodyConfig) (io.Reader, string,
 	for _, part := range bodyConfig {
 		if part.PlainTextValue != nil {
 			fw, err := w.CreateFormField(part.Key)
-			cobra.CheckErr(err)
-			_, err = fw.Write([]byte(*part.PlainTextValue))
-			cobra.CheckErr(err)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := fw.Write([]byte(*part.PlainTextValue)); err != nil {
+				return nil, "", err
+			}
 			continue
 		}
 
 		if part.BinaryFilePathValue != nil {
-			file, err := os.Open(*part.BinaryFilePathValue)
-			cobra.CheckErr(err)
-			defer file.Close()
-
-			fw, err := w.CreateFormFile(part.Key, filepath.Base(*part.BinaryFilePathValue))
-			cobra.CheckErr(err)
-			_, err = io.Copy(fw, file)
-			cobra.CheckErr(err)
+			if err := writeMultipartFile(w, part.Key, *part.BinaryFilePathValue); err != nil {
+				return nil, "", err
+			}
 		}
 	}
 
-	err := w.Close()
-	cobra.CheckErr(err)
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
 
 	return &b, w.FormDataContentType(), nil
 }
 
+func writeMultipartFile(w *multipart.Writer, key, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fw, err := w.CreateFormFile(key, filepath.Base(filePath))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, file)
+	return err
+}
+
 func setRequestHeaderFromConfig(req *http.Request, config *RequestConfig) {
 	req.Header.Set("Content-Type", strings.Trim(config.ContentType, " "))
 }
@@ -593,12 +1044,49 @@ func PromptNewExistentRequestConfig(label, collectionsPath string) *RequestConfi
 
 	request := prompts.Select(label, availableRequests)
 
-	wantedRequestPath := filepath.Join(collectionsPath, fmt.Sprintf("%s.json", request))
+	reqConfig, err := FindRequestConfig(collectionsPath, "", request)
+	cobra.CheckErr(err)
+	return reqConfig
+}
+
+// FindRequestConfig loads an existing request without prompting. The request
+// can be prefixed by its collection (e.g. "collection/request"), in which case
+// collection can be left empty. Requests of .http files are found by the
+// names they are listed with.
+func FindRequestConfig(collectionsPath, collection, request string) (*RequestConfig, error) {
+	requestName := filepath.Join(collection, request)
+	requestPath := filepath.Join(collectionsPath, requestName)
+
+	info, err := os.Stat(requestPath + ".json")
+	if err == nil && !info.IsDir() {
+		return NewRequestConfigFromFilePath(requestPath + ".json"), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
 
-	return NewRequestConfigFromFilePath(wantedRequestPath)
+	reqConfig, found, err := findHTTPFileRequest(requestPath)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("request %q does not exist", strings.TrimPrefix(requestName, string(filepath.Separator)))
+	}
+	return reqConfig, nil
 }
 
+// NewRequestConfigFromFilePath loads a request from a JSON file, or from a
+// .http file with a single request.
 func NewRequestConfigFromFilePath(filepath string) *RequestConfig {
+	if strings.HasSuffix(filepath, httpfile.Extension) {
+		reqConfig, found, err := loadHTTPFileRequest(filepath, "")
+		cobra.CheckErr(err)
+		if !found {
+			cobra.CheckErr(fmt.Errorf("%s must have a single request", filepath))
+		}
+		return reqConfig
+	}
+
 	jsonFile, err := os.Open(filepath)
 	cobra.CheckErr(err)
 	defer jsonFile.Close()
@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig are the TLS settings of the connections to the servers. They can
+// be set in the application configs, in the settings of a collection and in
+// a request, each one overriding the fields set by the previous ones.
+type TLSConfig struct {
+	// CACert is a PEM bundle of the certificate authorities to trust,
+	// instead of the ones of the system.
+	CACert     string `json:"ca_cert,omitempty" yaml:"caCert,omitempty"`
+	ClientCert string `json:"client_cert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty" yaml:"clientKey,omitempty"`
+	ServerName string `json:"server_name,omitempty" yaml:"serverName,omitempty"`
+	// MinVersion is the minimum TLS version: "1.0", "1.1", "1.2" or "1.3".
+	MinVersion string `json:"min_version,omitempty" yaml:"minVersion,omitempty"`
+	Insecure   bool   `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+}
+
+// applicationTLSConfig returns the TLS settings of the application configs,
+// which include the --insecure flag.
+func applicationTLSConfig() *TLSConfig {
+	return &TLSConfig{
+		CACert:     viper.GetString("tls.caCert"),
+		ClientCert: viper.GetString("tls.clientCert"),
+		ClientKey:  viper.GetString("tls.clientKey"),
+		ServerName: viper.GetString("tls.serverName"),
+		MinVersion: viper.GetString("tls.minVersion"),
+		Insecure:   viper.GetBool("tls.insecure"),
+	}
+}
+
+// merge returns the settings with the fields set in override replacing its
+// own. Insecure mode can only be enabled, never disabled, by an override.
+func (c *TLSConfig) merge(override *TLSConfig) *TLSConfig {
+	if override == nil {
+		return c
+	}
+	if c == nil {
+		c = &TLSConfig{}
+	}
+
+	merged := *c
+	if override.CACert != "" {
+		merged.CACert = override.CACert
+	}
+	if override.ClientCert != "" {
+		merged.ClientCert = override.ClientCert
+		merged.ClientKey = override.ClientKey
+	}
+	if override.ServerName != "" {
+		merged.ServerName = override.ServerName
+	}
+	if override.MinVersion != "" {
+		merged.MinVersion = override.MinVersion
+	}
+	merged.Insecure = merged.Insecure || override.Insecure
+	return &merged
+}
+
+func (c *TLSConfig) resolve(resolve func(string) string) *TLSConfig {
+	if c == nil {
+		return nil
+	}
+
+	return &TLSConfig{
+		CACert:     resolve(c.CACert),
+		ClientCert: resolve(c.ClientCert),
+		ClientKey:  resolve(c.ClientKey),
+		ServerName: resolve(c.ServerName),
+		MinVersion: c.MinVersion,
+		Insecure:   c.Insecure,
+	}
+}
+
+// relativeTo returns the settings with their relative certificate paths
+// joined to directory.
+func (c *TLSConfig) relativeTo(directory string) *TLSConfig {
+	if c == nil {
+		return nil
+	}
+
+	join := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(directory, path)
+	}
+	resolved := *c
+	resolved.CACert = join(c.CACert)
+	resolved.ClientCert = join(c.ClientCert)
+	resolved.ClientKey = join(c.ClientKey)
+	return &resolved
+}
+
+// build creates the configuration of the transport, loading the certificates.
+func (c *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.Insecure,
+	}
+
+	if c.MinVersion != "" {
+		version, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS min version %q, it must be 1.0, 1.1, 1.2 or 1.3", c.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificates: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", c.CACert)
+		}
+	}
+
+	if c.ClientKey != "" && c.ClientCert == "" {
+		return nil, fmt.Errorf("a client key requires a client certificate")
+	}
+	if c.ClientCert != "" {
+		// The key can be in the same file as the certificate.
+		keyFile := c.ClientKey
+		if keyFile == "" {
+			keyFile = c.ClientCert
+		}
+		certificate, err := tls.LoadX509KeyPair(c.ClientCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
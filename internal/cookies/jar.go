@@ -0,0 +1,369 @@
+package cookies
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the name of the cookie jar of a collection, in its
+	// directory.
+	FileName = ".cookies.txt"
+
+	httpOnlyPrefix = "#HttpOnly_"
+	fileHeader     = "# Netscape HTTP Cookie File\n# Generated by httpmate, it can be used with curl -b and -c.\n\n"
+)
+
+// Cookie is a cookie of the jar, with the fields of the Netscape cookies.txt
+// format.
+type Cookie struct {
+	Domain string
+	// IncludeSubdomains is false for the cookies which were set without a
+	// Domain attribute, which are only sent to the host which set them.
+	IncludeSubdomains bool
+	Path              string
+	Secure            bool
+	HTTPOnly          bool
+	// Expires is zero for session cookies, which are kept until the jar is
+	// cleared.
+	Expires time.Time
+	Name    string
+	Value   string
+}
+
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+func (c *Cookie) matches(u *url.URL, now time.Time) bool {
+	if c.expired(now) {
+		return false
+	}
+	if c.Secure && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != c.Domain && !(c.IncludeSubdomains && strings.HasSuffix(host, "."+c.Domain)) {
+		return false
+	}
+	return pathMatches(requestPath(u), c.Path)
+}
+
+// Jar is a cookie jar stored in a cookies.txt file, so it's kept between
+// runs and can be shared with curl. It implements http.CookieJar, and every
+// change is written to the file right away.
+type Jar struct {
+	path    string
+	mu      sync.Mutex
+	cookies []*Cookie
+}
+
+// Load reads the cookie jar of the file, which is created when the first
+// cookie is stored.
+func Load(path string) (*Jar, error) {
+	jar := &Jar{path: path}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return jar, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		cookie, err := parseLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie in %s, line %d: %w", path, lineNumber, err)
+		}
+		if cookie != nil {
+			jar.cookies = append(jar.cookies, cookie)
+		}
+	}
+	return jar, scanner.Err()
+}
+
+// All returns the cookies of the jar which haven't expired.
+func (j *Jar) All() []*Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	result := make([]*Cookie, 0, len(j.cookies))
+	for _, cookie := range j.cookies {
+		if !cookie.expired(now) {
+			result = append(result, cookie)
+		}
+	}
+	return result
+}
+
+// Set stores the cookie, replacing the one with the same name, domain and
+// path.
+func (j *Jar) Set(cookie *Cookie) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.set(cookie)
+	return j.save()
+}
+
+// Clear removes every cookie of the jar, or only the ones of a domain if it's
+// not empty.
+func (j *Jar) Clear(domain string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if domain == "" {
+		j.cookies = nil
+		return j.save()
+	}
+
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	kept := make([]*Cookie, 0, len(j.cookies))
+	for _, cookie := range j.cookies {
+		if cookie.Domain != domain {
+			kept = append(kept, cookie)
+		}
+	}
+	j.cookies = kept
+	return j.save()
+}
+
+// SetCookies stores the cookies of a response. Since http.CookieJar can't
+// return errors, the ones writing the file are only reported.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	for _, httpCookie := range cookies {
+		cookie, ok := newCookie(u, httpCookie, now)
+		if !ok {
+			continue
+		}
+		if cookie.expired(now) {
+			j.remove(cookie)
+			continue
+		}
+		j.set(cookie)
+	}
+
+	if err := j.save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving cookies:", err)
+	}
+}
+
+// Cookies returns the cookies to send in a request to the URL, the ones with
+// longer paths first.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	matching := make([]*Cookie, 0)
+	for _, cookie := range j.cookies {
+		if cookie.matches(u, now) {
+			matching = append(matching, cookie)
+		}
+	}
+	sort.SliceStable(matching, func(a, b int) bool {
+		return len(matching[a].Path) > len(matching[b].Path)
+	})
+
+	result := make([]*http.Cookie, 0, len(matching))
+	for _, cookie := range matching {
+		result = append(result, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return result
+}
+
+func (j *Jar) set(cookie *Cookie) {
+	for i, existing := range j.cookies {
+		if sameCookie(existing, cookie) {
+			j.cookies[i] = cookie
+			return
+		}
+	}
+	j.cookies = append(j.cookies, cookie)
+}
+
+func (j *Jar) remove(cookie *Cookie) {
+	kept := make([]*Cookie, 0, len(j.cookies))
+	for _, existing := range j.cookies {
+		if !sameCookie(existing, cookie) {
+			kept = append(kept, existing)
+		}
+	}
+	j.cookies = kept
+}
+
+func sameCookie(a, b *Cookie) bool {
+	return a.Name == b.Name && a.Domain == b.Domain && a.Path == b.Path
+}
+
+// save writes the jar, readable only by the user since cookies are usually
+// credentials. Expired cookies are dropped.
+func (j *Jar) save() error {
+	var content strings.Builder
+	content.WriteString(fileHeader)
+
+	now := time.Now()
+	for _, cookie := range j.cookies {
+		if cookie.expired(now) {
+			continue
+		}
+		content.WriteString(formatLine(cookie))
+		content.WriteString("\n")
+	}
+	return os.WriteFile(j.path, []byte(content.String()), 0600)
+}
+
+// newCookie creates the cookie of the jar from a cookie set by the response
+// to the URL, as defined by RFC 6265. Cookies for other domains are rejected,
+// and so are the ones for a top-level domain such as "com", which would be
+// sent to every site under it.
+func newCookie(u *url.URL, httpCookie *http.Cookie, now time.Time) (*Cookie, bool) {
+	host := strings.ToLower(u.Hostname())
+	cookie := &Cookie{
+		Domain:   host,
+		Path:     httpCookie.Path,
+		Secure:   httpCookie.Secure,
+		HTTPOnly: httpCookie.HttpOnly,
+		Name:     httpCookie.Name,
+		Value:    httpCookie.Value,
+	}
+
+	if domain := strings.TrimPrefix(strings.ToLower(httpCookie.Domain), "."); domain != "" && domain != host {
+		if net.ParseIP(host) != nil || !strings.HasSuffix(host, "."+domain) || !strings.Contains(domain, ".") {
+			return nil, false
+		}
+		cookie.Domain = domain
+		cookie.IncludeSubdomains = true
+	} else if domain != "" {
+		cookie.IncludeSubdomains = true
+	}
+
+	if cookie.Path == "" || !strings.HasPrefix(cookie.Path, "/") {
+		cookie.Path = defaultPath(u)
+	}
+
+	switch {
+	case httpCookie.MaxAge < 0:
+		cookie.Expires = now.Add(-time.Second)
+	case httpCookie.MaxAge > 0:
+		cookie.Expires = now.Add(time.Duration(httpCookie.MaxAge) * time.Second)
+	case !httpCookie.Expires.IsZero():
+		cookie.Expires = httpCookie.Expires
+		if !cookie.Expires.After(now) {
+			cookie.Expires = now.Add(-time.Second)
+		}
+	}
+	return cookie, true
+}
+
+func requestPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// defaultPath is the directory of the path of the request.
+func defaultPath(u *url.URL) string {
+	path := requestPath(u)
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func pathMatches(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// parseLine parses a line of a cookies.txt file. Comments and blank lines
+// return a nil cookie.
+func parseLine(line string) (*Cookie, error) {
+	httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+	line = strings.TrimPrefix(line, httpOnlyPrefix)
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+
+	fields := strings.Split(line, "\t")
+	if len(fields) != 7 {
+		return nil, fmt.Errorf("expected 7 tab separated fields, got %d", len(fields))
+	}
+
+	expires, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration %q", fields[4])
+	}
+
+	cookie := &Cookie{
+		Domain:            strings.TrimPrefix(strings.ToLower(fields[0]), "."),
+		IncludeSubdomains: strings.EqualFold(fields[1], "TRUE"),
+		Path:              fields[2],
+		Secure:            strings.EqualFold(fields[3], "TRUE"),
+		HTTPOnly:          httpOnly,
+		Name:              fields[5],
+		Value:             fields[6],
+	}
+	if expires > 0 {
+		cookie.Expires = time.Unix(expires, 0)
+	}
+	return cookie, nil
+}
+
+func formatLine(cookie *Cookie) string {
+	domain := cookie.Domain
+	if cookie.IncludeSubdomains {
+		domain = "." + domain
+	}
+	if cookie.HTTPOnly {
+		domain = httpOnlyPrefix + domain
+	}
+
+	expires := int64(0)
+	if !cookie.Expires.IsZero() {
+		expires = cookie.Expires.Unix()
+	}
+
+	return strings.Join([]string{
+		domain,
+		formatBool(cookie.IncludeSubdomains),
+		cookie.Path,
+		formatBool(cookie.Secure),
+		strconv.FormatInt(expires, 10),
+		cookie.Name,
+		cookie.Value,
+	}, "\t")
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,338 @@
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jarContent = "# Netscape HTTP Cookie File\n" +
+	"# https://curl.se/docs/http-cookies.html\n" +
+	"\n" +
+	"#HttpOnly_.example.com\tTRUE\t/\tTRUE\t4102444800\tsession_id\tabc\n" +
+	"api.example.com\tFALSE\t/v1\tFALSE\t0\ttheme\tdark\n" +
+	".Example.org\tTRUE\t/\tFALSE\t1000\texpired\tyes\n"
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(jarContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	jar, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*Cookie{
+		{
+			Domain:            "example.com",
+			IncludeSubdomains: true,
+			Path:              "/",
+			Secure:            true,
+			HTTPOnly:          true,
+			Expires:           time.Unix(4102444800, 0),
+			Name:              "session_id",
+			Value:             "abc",
+		},
+		{
+			Domain: "api.example.com",
+			Path:   "/v1",
+			Name:   "theme",
+			Value:  "dark",
+		},
+		{
+			Domain:            "example.org",
+			IncludeSubdomains: true,
+			Path:              "/",
+			Expires:           time.Unix(1000, 0),
+			Name:              "expired",
+			Value:             "yes",
+		},
+	}
+	if !reflect.DeepEqual(jar.cookies, expected) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, jar.cookies)
+	}
+
+	// Expired cookies are kept in the file until it's written, but never
+	// returned.
+	if len(jar.All()) != 2 {
+		t.Errorf("expected 2 cookies, got %d", len(jar.All()))
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(jarContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = jar.Set(&Cookie{Domain: "localhost", Path: "/", Name: "local", Value: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fileHeader +
+		"#HttpOnly_.example.com\tTRUE\t/\tTRUE\t4102444800\tsession_id\tabc\n" +
+		"api.example.com\tFALSE\t/v1\tFALSE\t0\ttheme\tdark\n" +
+		"localhost\tFALSE\t/\tFALSE\t0\tlocal\t1\n"
+	if string(content) != expected {
+		t.Errorf("expected the file\n%s\ngot\n%s", expected, content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the file to be readable only by the user, got %s", info.Mode().Perm())
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.All(), jar.All()) {
+		t.Errorf("expected the same cookies after reloading\n%+v\ngot\n%+v", jar.All(), reloaded.All())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{content: "example.com\tFALSE\t/\n", expected: "line 1: expected 7 tab separated fields, got 3"},
+		{content: "# comment\nexample.com\tFALSE\t/\tFALSE\tnever\tname\tvalue\n", expected: `line 2: invalid expiration "never"`},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path)
+		if err == nil || !strings.HasSuffix(err.Error(), test.expected) {
+			t.Errorf("expected the error %q, got %v", test.expected, err)
+		}
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	jar, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jar.All()) != 0 {
+		t.Error("expected an empty jar")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the file not to be created until a cookie is stored")
+	}
+}
+
+func TestSetCookies(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		url      string
+		cookie   *http.Cookie
+		expected *Cookie
+	}{
+		{
+			name:     "host only",
+			url:      "https://api.example.com/v1/users",
+			cookie:   &http.Cookie{Name: "a", Value: "1"},
+			expected: &Cookie{Domain: "api.example.com", Path: "/v1", Name: "a", Value: "1"},
+		},
+		{
+			name:     "domain attribute of the host",
+			url:      "https://API.example.com/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "api.example.com", Path: "/"},
+			expected: &Cookie{Domain: "api.example.com", IncludeSubdomains: true, Path: "/", Name: "a", Value: "1"},
+		},
+		{
+			name:     "parent domain",
+			url:      "https://api.example.com/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: ".example.com", Secure: true, HttpOnly: true},
+			expected: &Cookie{Domain: "example.com", IncludeSubdomains: true, Path: "/", Secure: true, HTTPOnly: true, Name: "a", Value: "1"},
+		},
+		{
+			name:     "relative path",
+			url:      "https://example.com/a/b",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Path: "c"},
+			expected: &Cookie{Domain: "example.com", Path: "/a", Name: "a", Value: "1"},
+		},
+		{
+			name:     "other domain",
+			url:      "https://example.com/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "example.org"},
+			expected: nil,
+		},
+		{
+			name:     "subdomain of the host",
+			url:      "https://example.com/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "api.example.com"},
+			expected: nil,
+		},
+		{
+			name:     "top-level domain",
+			url:      "https://example.com/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "com"},
+			expected: nil,
+		},
+		{
+			name:     "domain of an IP",
+			url:      "http://10.0.0.1/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "0.0.1"},
+			expected: nil,
+		},
+		{
+			name:     "single-label host",
+			url:      "http://localhost:8080/",
+			cookie:   &http.Cookie{Name: "a", Value: "1", Domain: "localhost"},
+			expected: &Cookie{Domain: "localhost", IncludeSubdomains: true, Path: "/", Name: "a", Value: "1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookie, ok := newCookie(mustParseURL(t, test.url), test.cookie, now)
+			if test.expected == nil {
+				if ok {
+					t.Errorf("expected the cookie to be rejected, got %+v", cookie)
+				}
+				return
+			}
+			if !ok || !reflect.DeepEqual(cookie, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, cookie)
+			}
+		})
+	}
+}
+
+func TestSetCookiesExpiration(t *testing.T) {
+	jar, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := mustParseURL(t, "https://example.com/")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "1"},
+		{Name: "max_age", Value: "1", MaxAge: 60},
+		{Name: "expires", Value: "1", Expires: time.Now().Add(time.Hour)},
+		{Name: "past", Value: "1", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	names := map[string]bool{}
+	for _, cookie := range jar.All() {
+		names[cookie.Name] = true
+		if cookie.Name == "session" && !cookie.Expires.IsZero() {
+			t.Error("expected a session cookie")
+		}
+	}
+	if !reflect.DeepEqual(names, map[string]bool{"session": true, "max_age": true, "expires": true}) {
+		t.Errorf("unexpected cookies %v", names)
+	}
+
+	// A cookie which has expired removes the stored one.
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "", MaxAge: -1}})
+	for _, cookie := range jar.All() {
+		if cookie.Name == "session" {
+			t.Error("expected the session cookie to be removed")
+		}
+	}
+
+	// The same cookie replaces the stored one.
+	jar.SetCookies(u, []*http.Cookie{{Name: "max_age", Value: "2", MaxAge: 60}})
+	values := map[string]string{}
+	for _, cookie := range jar.Cookies(u) {
+		values[cookie.Name] = cookie.Value
+	}
+	if !reflect.DeepEqual(values, map[string]string{"max_age": "2", "expires": "1"}) {
+		t.Errorf("expected the cookie to be replaced, got %v", values)
+	}
+}
+
+func TestCookies(t *testing.T) {
+	jar := &Jar{path: filepath.Join(t.TempDir(), FileName), cookies: []*Cookie{
+		{Domain: "example.com", IncludeSubdomains: true, Path: "/", Name: "all", Value: "1"},
+		{Domain: "example.com", Path: "/", Name: "host", Value: "1"},
+		{Domain: "api.example.com", Path: "/v1", Name: "v1", Value: "1"},
+		{Domain: "api.example.com", Path: "/v1/users/", Name: "users", Value: "1"},
+		{Domain: "example.com", IncludeSubdomains: true, Path: "/", Secure: true, Name: "secure", Value: "1"},
+		{Domain: "example.com", IncludeSubdomains: true, Path: "/", Expires: time.Now().Add(-time.Minute), Name: "expired", Value: "1"},
+	}}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://example.com", expected: "all=1; host=1; secure=1"},
+		{url: "http://example.com/a", expected: "all=1; host=1"},
+		{url: "https://api.example.com/", expected: "all=1; secure=1"},
+		{url: "https://api.example.com/v1", expected: "v1=1; all=1; secure=1"},
+		{url: "https://API.example.com/v1/users/1", expected: "users=1; v1=1; all=1; secure=1"},
+		{url: "https://api.example.com/v10", expected: "all=1; secure=1"},
+		{url: "https://api.example.com/v1/users", expected: "v1=1; all=1; secure=1"},
+		{url: "https://notexample.com/", expected: ""},
+		{url: "https://a.api.example.com/v1", expected: "all=1; secure=1"},
+	}
+
+	for _, test := range tests {
+		cookies := jar.Cookies(mustParseURL(t, test.url))
+		pairs := make([]string, 0, len(cookies))
+		for _, cookie := range cookies {
+			pairs = append(pairs, cookie.String())
+		}
+		if result := strings.Join(pairs, "; "); result != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.url, test.expected, result)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	jar := &Jar{path: filepath.Join(t.TempDir(), FileName), cookies: []*Cookie{
+		{Domain: "example.com", Path: "/", Name: "a", Value: "1"},
+		{Domain: "example.org", Path: "/", Name: "b", Value: "1"},
+	}}
+
+	if err := jar.Clear(".Example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if cookies := jar.All(); len(cookies) != 1 || cookies[0].Domain != "example.org" {
+		t.Errorf("expected only the cookies of the domain to be removed, got %+v", cookies)
+	}
+
+	if err := jar.Clear(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(jar.All()) != 0 {
+		t.Error("expected every cookie to be removed")
+	}
+}
@@ -0,0 +1,447 @@
+package curl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+)
+
+// Options that take a value, mapped to their canonical long name.
+var valueOptions = map[string]string{
+	"-X":                "--request",
+	"--request":         "--request",
+	"-H":                "--header",
+	"--header":          "--header",
+	"-d":                "--data",
+	"--data":            "--data",
+	"--data-raw":        "--data-raw",
+	"--data-binary":     "--data-binary",
+	"--data-ascii":      "--data",
+	"--data-urlencode":  "--data-urlencode",
+	"-F":                "--form",
+	"--form":            "--form",
+	"--form-string":     "--form-string",
+	"-u":                "--user",
+	"--user":            "--user",
+	"-b":                "--cookie",
+	"--cookie":          "--cookie",
+	"-A":                "--user-agent",
+	"--user-agent":      "--user-agent",
+	"-e":                "--referer",
+	"--referer":         "--referer",
+	"--url":             "--url",
+	"-o":                "--output",
+	"--output":          "--output",
+	"-m":                "--max-time",
+	"--max-time":        "--max-time",
+	"--connect-timeout": "--connect-timeout",
+	"-x":                "--proxy",
+	"--proxy":           "--proxy",
+	"--cacert":          "--cacert",
+	"-E":                "--cert",
+	"--cert":            "--cert",
+	"--key":             "--key",
+	"-w":                "--write-out",
+	"--write-out":       "--write-out",
+	"--retry":           "--retry",
+	"--oauth2-bearer":   "--oauth2-bearer",
+}
+
+// Options without value, mapped to their canonical long name.
+var flagOptions = map[string]string{
+	"-G":           "--get",
+	"--get":        "--get",
+	"-I":           "--head",
+	"--head":       "--head",
+	"--compressed": "--compressed",
+	"-s":           "--silent",
+	"--silent":     "--silent",
+	"-S":           "--show-error",
+	"--show-error": "--show-error",
+	"-L":           "--location",
+	"--location":   "--location",
+	"-k":           "--insecure",
+	"--insecure":   "--insecure",
+	"-v":           "--verbose",
+	"--verbose":    "--verbose",
+	"-i":           "--include",
+	"--include":    "--include",
+	"-f":           "--fail",
+	"--fail":       "--fail",
+	"--http1.1":    "--http1.1",
+	"--http2":      "--http2",
+	"--basic":      "--basic",
+	"--digest":     "--digest",
+}
+
+// Options that are accepted but have no equivalent in a request config.
+var ignoredOptions = map[string]bool{
+	"--silent":     true,
+	"--show-error": true,
+	"--verbose":    true,
+	"--include":    true,
+	"--fail":       true,
+	"--output":     true,
+	"--write-out":  true,
+	"--http1.1":    true,
+	"--http2":      true,
+	"--location":   true,
+	"--basic":      true,
+	// Go's HTTP client already requests and decompresses gzip responses, as
+	// long as Accept-Encoding isn't set (so it's dropped from the headers).
+	"--compressed": true,
+}
+
+type option struct {
+	name  string
+	value string
+}
+
+type dataPart struct {
+	value    string
+	isBinary bool
+	// isURLEncoded is set for the parts of --data-urlencode, which are
+	// encoded as fields.
+	isURLEncoded bool
+}
+
+// Parse converts a curl command line into a request config. The returned
+// warnings list the options that could not be translated.
+func Parse(command string) (*configs.RequestConfig, []string, error) {
+	args, err := splitArgs(strings.TrimSpace(command))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+
+	options, urls, warnings, err := parseOptions(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, nil, fmt.Errorf("no URL found in curl command")
+	}
+	if len(urls) > 1 {
+		warnings = append(warnings, fmt.Sprintf("only the first URL was imported, ignored %s", strings.Join(urls[1:], ", ")))
+	}
+
+	config := &configs.RequestConfig{
+		QueryParams: map[string]string{},
+		Headers:     map[string]string{},
+	}
+
+	method := ""
+	useGet := false
+	digest := false
+	data := make([]dataPart, 0)
+	formParts := make([]*configs.MultipartBodyConfig, 0)
+
+	for _, opt := range options {
+		switch opt.name {
+		case "--request":
+			method = strings.ToUpper(opt.value)
+		case "--header":
+			key, value, found := strings.Cut(opt.value, ":")
+			if !found {
+				warnings = append(warnings, fmt.Sprintf("ignored invalid header %q", opt.value))
+				continue
+			}
+			key = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+			if strings.EqualFold(key, "Content-Type") {
+				config.ContentType = value
+				continue
+			}
+			// Go only decompresses the responses when it requests the
+			// compression itself, so the copied encodings would be left
+			// compressed.
+			if strings.EqualFold(key, "Accept-Encoding") {
+				continue
+			}
+			if strings.EqualFold(key, "Authorization") {
+				if headerAuth := parseAuthorizationHeader(value); headerAuth != nil {
+					config.Auth = headerAuth
+					continue
+				}
+			}
+			config.Headers[key] = value
+		case "--data", "--data-raw":
+			if opt.name == "--data" && strings.HasPrefix(opt.value, "@") {
+				data = append(data, dataPart{value: opt.value[1:], isBinary: true})
+				continue
+			}
+			data = append(data, dataPart{value: opt.value})
+		case "--data-binary":
+			if strings.HasPrefix(opt.value, "@") {
+				data = append(data, dataPart{value: opt.value[1:], isBinary: true})
+				continue
+			}
+			data = append(data, dataPart{value: opt.value})
+		case "--data-urlencode":
+			data = append(data, dataPart{value: encodeDataURLEncode(opt.value), isURLEncoded: true})
+		case "--form", "--form-string":
+			part, err := parseFormPart(opt.value, opt.name == "--form-string")
+			if err != nil {
+				return nil, nil, err
+			}
+			formParts = append(formParts, part)
+		case "--user":
+			username, password, _ := strings.Cut(opt.value, ":")
+			config.Auth = &auth.Auth{Type: auth.TypeBasic, Username: username, Password: password}
+		case "--digest":
+			digest = true
+		case "--oauth2-bearer":
+			config.Auth = &auth.Auth{Type: auth.TypeBearer, Token: opt.value}
+		case "--cookie":
+			if !strings.Contains(opt.value, "=") {
+				warnings = append(warnings, fmt.Sprintf("ignored cookie file %q", opt.value))
+				continue
+			}
+			config.Headers["Cookie"] = opt.value
+		case "--user-agent":
+			config.Headers["User-Agent"] = opt.value
+		case "--referer":
+			config.Headers["Referer"] = opt.value
+		case "--get":
+			useGet = true
+		case "--head":
+			method = http.MethodHead
+		default:
+			if !ignoredOptions[opt.name] {
+				warnings = append(warnings, fmt.Sprintf("ignored unsupported option %s", opt.name))
+			}
+		}
+	}
+
+	if digest && config.Auth != nil && config.Auth.Type == auth.TypeBasic {
+		config.Auth.Type = auth.TypeDigest
+	}
+
+	if err := setURL(config, urls[0]); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case useGet:
+		for _, part := range data {
+			if part.isBinary {
+				warnings = append(warnings, fmt.Sprintf("ignored data file @%s with --get", part.value))
+				continue
+			}
+			values, err := url.ParseQuery(part.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid query data %q: %w", part.value, err)
+			}
+			for key := range values {
+				config.QueryParams[key] = values.Get(key)
+			}
+		}
+		if method == "" {
+			method = http.MethodGet
+		}
+	case len(formParts) > 0:
+		config.ContentType = string(configs.ContentTypeMultipartFormData)
+		config.Body.MultipartBody = formParts
+	case len(data) > 0:
+		setDataBody(config, data)
+	}
+
+	if method == "" {
+		method = http.MethodGet
+		if config.Body.RawBody != nil || config.Body.BinaryFileBody != nil ||
+			config.Body.MultipartBody != nil || config.Body.FormURLEncoded != nil {
+			method = http.MethodPost
+		}
+	}
+	config.Method = method
+
+	return config, warnings, nil
+}
+
+// parseAuthorizationHeader converts bearer and basic Authorization headers
+// into the auth of the request. Other schemes are kept as headers.
+func parseAuthorizationHeader(value string) *auth.Auth {
+	scheme, credentials, _ := strings.Cut(value, " ")
+	credentials = strings.TrimSpace(credentials)
+	if credentials == "" {
+		return nil
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return &auth.Auth{Type: auth.TypeBearer, Token: credentials}
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return nil
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return nil
+		}
+		return &auth.Auth{Type: auth.TypeBasic, Username: username, Password: password}
+	}
+	return nil
+}
+
+func parseOptions(args []string) ([]option, []string, []string, error) {
+	options := make([]option, 0)
+	urls := make([]string, 0)
+	warnings := make([]string, 0)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			urls = append(urls, arg)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue := strings.Cut(arg, "=")
+			if canonical, ok := valueOptions[name]; ok {
+				if !hasValue {
+					if i+1 >= len(args) {
+						return nil, nil, nil, fmt.Errorf("option %s requires a value", name)
+					}
+					i++
+					value = args[i]
+				}
+				if canonical == "--url" {
+					urls = append(urls, value)
+					continue
+				}
+				options = append(options, option{name: canonical, value: value})
+				continue
+			}
+			if canonical, ok := flagOptions[name]; ok {
+				options = append(options, option{name: canonical})
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("ignored unknown option %s", name))
+			continue
+		}
+
+		// Short options can be combined (-sSL) or have their value attached
+		// (-XPOST).
+		for j := 1; j < len(arg); j++ {
+			name := "-" + string(arg[j])
+			if canonical, ok := valueOptions[name]; ok {
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						return nil, nil, nil, fmt.Errorf("option %s requires a value", name)
+					}
+					i++
+					value = args[i]
+				}
+				options = append(options, option{name: canonical, value: value})
+				break
+			}
+			if canonical, ok := flagOptions[name]; ok {
+				options = append(options, option{name: canonical})
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("ignored unknown option %s", name))
+		}
+	}
+
+	return options, urls, warnings, nil
+}
+
+func setURL(config *configs.RequestConfig, rawURL string) error {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	config.Domain = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	config.Path = u.Path
+	for key, values := range u.Query() {
+		config.QueryParams[key] = values[0]
+	}
+	return nil
+}
+
+func setDataBody(config *configs.RequestConfig, data []dataPart) {
+	if len(data) == 1 && data[0].isBinary {
+		if config.ContentType == "" {
+			config.ContentType = string(configs.ContentTypeOctetStream)
+		}
+		config.Body.BinaryFileBody = &data[0].value
+		return
+	}
+
+	values := make([]string, 0, len(data))
+	urlEncoded := true
+	for _, part := range data {
+		value := part.value
+		if part.isBinary {
+			value = "@" + value
+		}
+		values = append(values, value)
+		urlEncoded = urlEncoded && part.isURLEncoded
+	}
+	body := strings.Join(values, "&")
+
+	if config.ContentType == "" {
+		config.ContentType = string(configs.ContentTypeFormURLEncoded)
+	}
+
+	// Like curl, the data of -d is sent as it is, even if it isn't a valid
+	// form. Only the fields of --data-urlencode are known to be a form.
+	if urlEncoded && configs.ContentType(config.ContentType) == configs.ContentTypeFormURLEncoded {
+		if form, err := url.ParseQuery(body); err == nil {
+			config.Body.FormURLEncoded = map[string]string{}
+			for key := range form {
+				config.Body.FormURLEncoded[key] = form.Get(key)
+			}
+			return
+		}
+	}
+
+	config.Body.RawBody = &body
+}
+
+func parseFormPart(value string, isString bool) (*configs.MultipartBodyConfig, error) {
+	key, content, found := strings.Cut(value, "=")
+	if !found {
+		return nil, fmt.Errorf("invalid form part %q, expected key=value", value)
+	}
+
+	part := &configs.MultipartBodyConfig{Key: key}
+	if !isString && strings.HasPrefix(content, "@") {
+		// Drop the attributes of the file, such as ";type=image/png".
+		filePath, _, _ := strings.Cut(content[1:], ";")
+		part.BinaryFilePathValue = &filePath
+		return part, nil
+	}
+
+	part.PlainTextValue = &content
+	return part, nil
+}
+
+// encodeDataURLEncode mimics curl's --data-urlencode, which encodes the
+// content after the first '=' (or the whole content if there is none).
+func encodeDataURLEncode(value string) string {
+	key, content, found := strings.Cut(value, "=")
+	if !found {
+		return url.QueryEscape(value)
+	}
+	if key == "" {
+		return url.QueryEscape(content)
+	}
+	return fmt.Sprintf("%s=%s", key, url.QueryEscape(content))
+}
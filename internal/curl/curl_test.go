@@ -0,0 +1,205 @@
+package curl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+)
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected configs.RequestConfig
+		warnings []string
+	}{
+		{
+			name:    "headers",
+			command: `curl 'https://example.com/users?page=2' -H 'Accept: application/json' -H 'X-Trace:abc' -H 'Content-Type: application/json'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/users",
+				Method:      "GET",
+				ContentType: "application/json",
+				QueryParams: map[string]string{"page": "2"},
+				Headers:     map[string]string{"Accept": "application/json", "X-Trace": "abc"},
+			},
+		},
+		{
+			name:    "bearer authorization header",
+			command: `curl https://example.com -H 'Authorization: Bearer abc.def'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Method:      "GET",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Auth:        &auth.Auth{Type: auth.TypeBearer, Token: "abc.def"},
+			},
+		},
+		{
+			name:    "data is sent as it is",
+			command: `curl https://example.com/login -d 'user=john&tags=a&tags=b'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/login",
+				Method:      "POST",
+				ContentType: "application/x-www-form-urlencoded",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Body:        configs.RequestBodyConfig{RawBody: stringPointer("user=john&tags=a&tags=b")},
+			},
+		},
+		{
+			name:    "json data",
+			command: `curl -X PUT https://example.com/users/1 -H 'Content-Type: application/json' --data-raw '{"name":"john"}'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/users/1",
+				Method:      "PUT",
+				ContentType: "application/json",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Body:        configs.RequestBodyConfig{RawBody: stringPointer(`{"name":"john"}`)},
+			},
+		},
+		{
+			name:    "urlencoded data is a form",
+			command: `curl https://example.com/search --data-urlencode 'q=a b' --data-urlencode 'page=1'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/search",
+				Method:      "POST",
+				ContentType: "application/x-www-form-urlencoded",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Body:        configs.RequestBodyConfig{FormURLEncoded: map[string]string{"q": "a b", "page": "1"}},
+			},
+		},
+		{
+			name:    "data file",
+			command: `curl https://example.com/upload -d @payload.bin`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/upload",
+				Method:      "POST",
+				ContentType: "application/octet-stream",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Body:        configs.RequestBodyConfig{BinaryFileBody: stringPointer("payload.bin")},
+			},
+		},
+		{
+			name:    "data with --get",
+			command: `curl -G https://example.com/search -d q=john`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/search",
+				Method:      "GET",
+				QueryParams: map[string]string{"q": "john"},
+				Headers:     map[string]string{},
+			},
+		},
+		{
+			name:    "form",
+			command: `curl https://example.com/upload -F 'name=john' -F 'avatar=@me.png;type=image/png'`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/upload",
+				Method:      "POST",
+				ContentType: "multipart/form-data",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Body: configs.RequestBodyConfig{MultipartBody: []*configs.MultipartBodyConfig{
+					{Key: "name", PlainTextValue: stringPointer("john")},
+					{Key: "avatar", BinaryFilePathValue: stringPointer("me.png")},
+				}},
+			},
+		},
+		{
+			name:    "user",
+			command: `curl -u john:secret https://example.com`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Method:      "GET",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Auth:        &auth.Auth{Type: auth.TypeBasic, Username: "john", Password: "secret"},
+			},
+		},
+		{
+			name:    "digest user",
+			command: `curl --digest --user john:secret https://example.com`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Method:      "GET",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+				Auth:        &auth.Auth{Type: auth.TypeDigest, Username: "john", Password: "secret"},
+			},
+		},
+		{
+			name:    "compressed copy as cURL",
+			command: `curl 'https://example.com/' -H 'Accept-Encoding: gzip, deflate, br, zstd' -H 'User-Agent: Firefox' --compressed`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/",
+				Method:      "GET",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{"User-Agent": "Firefox"},
+			},
+		},
+		{
+			name:    "combined short options and unknown options",
+			command: `curl -sSL -XDELETE --frobnicate https://example.com/users/1`,
+			expected: configs.RequestConfig{
+				Domain:      "https://example.com",
+				Path:        "/users/1",
+				Method:      "DELETE",
+				QueryParams: map[string]string{},
+				Headers:     map[string]string{},
+			},
+			warnings: []string{"ignored unknown option --frobnicate"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, warnings, err := Parse(test.command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*config, test.expected) {
+				t.Errorf("expected\n%+v\ngot\n%+v", test.expected, *config)
+			}
+			if len(warnings) != 0 || len(test.warnings) != 0 {
+				if !reflect.DeepEqual(warnings, test.warnings) {
+					t.Errorf("expected the warnings %v, got %v", test.warnings, warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{command: `curl -H 'Accept: */*'`, expected: "no URL found in curl command"},
+		{command: `curl https://example.com -H`, expected: "option -H requires a value"},
+		{command: `curl https://example.com -F name`, expected: `invalid form part "name", expected key=value`},
+	}
+
+	for _, test := range tests {
+		_, _, err := Parse(test.command)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected the error %q, got %v", test.command, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package curl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// splitArgs splits a command line into its arguments the same way a POSIX
+// shell would, handling single quotes, double quotes, ANSI-C quotes ($'...'),
+// backslash escapes and line continuations.
+func splitArgs(command string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(command) {
+				return nil, fmt.Errorf("unexpected end of command after '\\'")
+			}
+			i++
+			if command[i] == '\n' {
+				continue
+			}
+			if command[i] == '\r' && i+1 < len(command) && command[i+1] == '\n' {
+				i++
+				continue
+			}
+			current.WriteByte(command[i])
+			inArg = true
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			value, consumed, err := readANSIQuoted(command[i+2:])
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			i += consumed + 1
+			inArg = true
+		case c == '"':
+			value, consumed, err := readDoubleQuoted(command[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			i += consumed
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// readDoubleQuoted reads the content of a double quoted string, returning it
+// and the number of bytes consumed, including the closing quote.
+func readDoubleQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+				i++
+				if s[i] != '\n' {
+					b.WriteByte(s[i])
+				}
+				continue
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated double quote")
+}
+
+// readANSIQuoted reads the content of a $'...' string, returning it and the
+// number of bytes consumed, including the closing quote.
+func readANSIQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			return b.String(), i + 1, nil
+		}
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'x':
+			end := i + 1
+			for end < len(s) && end < i+3 && isHex(s[end]) {
+				end++
+			}
+			value, err := strconv.ParseUint(s[i+1:end], 16, 8)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape sequence \\x%s", s[i+1:end])
+			}
+			b.WriteByte(byte(value))
+			i = end - 1
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			end := i + 1
+			for end < len(s) && end < i+1+size && isHex(s[end]) {
+				end++
+			}
+			value, err := strconv.ParseUint(s[i+1:end], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(value)) {
+				return "", 0, fmt.Errorf("invalid escape sequence \\%c%s", s[i], s[i+1:end])
+			}
+			b.WriteRune(rune(value))
+			i = end - 1
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated $' quote")
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,197 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SniffLength is how much of a body is needed to detect whether it's binary.
+const SniffLength = 512
+
+// IsBinary reports whether a body isn't text, from its content type or, if
+// it's unknown, from its first bytes.
+func IsBinary(contentType string, sniffed []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" || mediaType == "application/octet-stream" {
+		return isBinaryContent(sniffed)
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"),
+		strings.HasSuffix(mediaType, "+yaml"):
+		return false
+	}
+
+	switch mediaType {
+	case "application/json",
+		"application/xml",
+		"application/javascript",
+		"application/x-www-form-urlencoded",
+		"application/yaml",
+		"application/x-yaml",
+		"application/graphql",
+		"application/x-ndjson",
+		"image/svg+xml":
+		return false
+	}
+	return true
+}
+
+// isBinaryContent reports whether the bytes aren't text. The last rune may be
+// cut by the sniffing, so it isn't checked.
+func isBinaryContent(sniffed []byte) bool {
+	if len(sniffed) == 0 {
+		return false
+	}
+	if !strings.HasPrefix(http.DetectContentType(sniffed), "text/") {
+		return true
+	}
+
+	for len(sniffed) > 0 {
+		r, size := utf8.DecodeRune(sniffed)
+		if r == utf8.RuneError && size == 1 && len(sniffed) >= utf8.UTFMax {
+			return true
+		}
+		sniffed = sniffed[size:]
+	}
+	return false
+}
+
+// FileName returns the name of the file of the response, from its
+// Content-Disposition header or, if it has none, from its URL. The name
+// never contains a directory.
+func FileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := sanitize(params["filename"]); name != "" {
+			return name
+		}
+	}
+
+	if resp.Request != nil {
+		if name := sanitize(path.Base(resp.Request.URL.Path)); name != "" {
+			return name
+		}
+	}
+
+	name := "response"
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if extensions, err := mime.ExtensionsByType(mediaType); err == nil && len(extensions) > 0 {
+		name += extensions[0]
+	}
+	return name
+}
+
+func sanitize(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// UniquePath returns the path of the file in the directory, with a numeric
+// suffix (e.g. "report-1.pdf") if a file with the name already exists.
+func UniquePath(directory, name string) string {
+	extension := filepath.Ext(name)
+	base := strings.TrimSuffix(name, extension)
+
+	candidate := filepath.Join(directory, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = filepath.Join(directory, fmt.Sprintf("%s-%d%s", base, i, extension))
+	}
+}
+
+// Save streams the body to the file, through a temporary file so an
+// interrupted download doesn't leave a partial file. The progress is written
+// to progress, if it's not nil. It returns the number of bytes saved.
+func Save(filePath string, body io.Reader, size int64, progress io.Writer) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("could not save the body to %s: %w", filePath, err)
+	}
+	defer os.Remove(tmp.Name())
+	// Temporary files are only readable by their owner.
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	var writer io.Writer = tmp
+	if progress != nil {
+		bar := &progressWriter{out: progress, name: filepath.Base(filePath), size: size}
+		defer bar.finish()
+		writer = io.MultiWriter(tmp, bar)
+	}
+
+	written, err := io.Copy(writer, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	return written, os.Rename(tmp.Name(), filePath)
+}
+
+// progressWriter prints how much of the body was written, at most every
+// tenth of a second.
+type progressWriter struct {
+	out       io.Writer
+	name      string
+	size      int64
+	written   int64
+	lastPrint time.Time
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.written += int64(len(data))
+	if time.Since(p.lastPrint) >= 100*time.Millisecond {
+		p.print()
+	}
+	return len(data), nil
+}
+
+func (p *progressWriter) print() {
+	p.lastPrint = time.Now()
+	if p.size <= 0 {
+		fmt.Fprintf(p.out, "\rDownloading %s: %s", p.name, FormatSize(p.written))
+		return
+	}
+	fmt.Fprintf(p.out, "\rDownloading %s: %s of %s (%d%%)", p.name, FormatSize(p.written), FormatSize(p.size), p.written*100/p.size)
+}
+
+func (p *progressWriter) finish() {
+	p.print()
+	fmt.Fprintln(p.out)
+}
+
+// FormatSize formats the number of bytes, e.g. "1.5 MB".
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
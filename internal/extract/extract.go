@@ -0,0 +1,103 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/joaocgduarte/httpmate/internal/jsonpath"
+)
+
+// Extraction reads a value from a response into a variable. Exactly one of
+// the sources (JSON path, header, body regex or cookie) must be set.
+type Extraction struct {
+	Variable  string `json:"variable"`
+	JSONPath  string `json:"json_path,omitempty"`
+	Header    string `json:"header,omitempty"`
+	BodyRegex string `json:"body_regex,omitempty"`
+	Cookie    string `json:"cookie,omitempty"`
+}
+
+// Apply extracts every value from the response. The body is passed
+// separately since the response body has already been consumed. The values
+// which could not be extracted are reported as errors.
+func Apply(extractions []Extraction, resp *http.Response, body []byte) (map[string]string, []error) {
+	values := map[string]string{}
+	errs := make([]error, 0)
+
+	for _, extraction := range extractions {
+		value, err := extraction.extract(resp, body)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", extraction.Variable, err))
+			continue
+		}
+		values[extraction.Variable] = value
+	}
+
+	return values, errs
+}
+
+func (e Extraction) extract(resp *http.Response, body []byte) (string, error) {
+	if e.Variable == "" {
+		return "", fmt.Errorf("the variable of the extraction is missing")
+	}
+
+	switch {
+	case e.JSONPath != "":
+		return extractJSONPath(e.JSONPath, body)
+	case e.Header != "":
+		values, ok := resp.Header[http.CanonicalHeaderKey(e.Header)]
+		if !ok || len(values) == 0 {
+			return "", fmt.Errorf("header %s not found", e.Header)
+		}
+		return values[0], nil
+	case e.BodyRegex != "":
+		return extractBodyRegex(e.BodyRegex, body)
+	case e.Cookie != "":
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == e.Cookie {
+				return cookie.Value, nil
+			}
+		}
+		return "", fmt.Errorf("cookie %s not found", e.Cookie)
+	}
+
+	return "", fmt.Errorf("the extraction has no json_path, header, body_regex or cookie")
+}
+
+// extractJSONPath returns strings as they are, and any other value as JSON.
+func extractJSONPath(path string, body []byte) (string, error) {
+	value, err := jsonpath.Lookup(body, path)
+	if err != nil {
+		return "", fmt.Errorf("json path %s: %w", path, err)
+	}
+
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// extractBodyRegex returns the first capture group of the expression, or the
+// whole match if it has no groups.
+func extractBodyRegex(expression string, body []byte) (string, error) {
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex: %w", err)
+	}
+
+	match := regex.FindSubmatch(body)
+	if match == nil {
+		return "", fmt.Errorf("body does not match %q", expression)
+	}
+	if len(match) > 1 {
+		return string(match[1]), nil
+	}
+	return string(match[0]), nil
+}
@@ -4,11 +4,18 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/joaocgduarte/httpmate/internal/httpfile"
 	"github.com/spf13/cobra"
 )
 
+var unsafeFileNameRegex = regexp.MustCompile(`[/\\:*?"<>|]+`)
+
+// GetFilesFromDirectoryWithoutExtension lists the requests of a directory.
+// Each request of a .http file with multiple requests is listed as
+// "file/request".
 func GetFilesFromDirectoryWithoutExtension(parentDirectory string) []string {
 	jsonFiles := []string{}
 	err := filepath.WalkDir(parentDirectory, func(path string, d os.DirEntry, err error) error {
@@ -17,10 +24,20 @@ func GetFilesFromDirectoryWithoutExtension(parentDirectory string) []string {
 			return nil
 		}
 
+		if IsHidden(d.Name()) {
+			return skipHidden(d)
+		}
+
 		if !d.IsDir() {
 			path = path[:len(path)-len(filepath.Ext(path))]
 			path = strings.Replace(path, parentDirectory, "", -1)
-			jsonFiles = append(jsonFiles, path)
+
+			if filepath.Ext(d.Name()) != httpfile.Extension {
+				jsonFiles = append(jsonFiles, path)
+				return nil
+			}
+
+			jsonFiles = append(jsonFiles, httpFileRequests(parentDirectory, path)...)
 		}
 		return nil
 	})
@@ -29,6 +46,21 @@ func GetFilesFromDirectoryWithoutExtension(parentDirectory string) []string {
 	return jsonFiles
 }
 
+func httpFileRequests(parentDirectory, path string) []string {
+	names, err := httpfile.RequestNames(filepath.Join(parentDirectory, path+httpfile.Extension))
+	cobra.CheckErr(err)
+
+	if len(names) == 1 {
+		return []string{path}
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, filepath.Join(path, name))
+	}
+	return result
+}
+
 func GetSubDirectories(parentDirectory string) []string {
 	result := make([]string, 0)
 	err := filepath.WalkDir(parentDirectory, func(path string, d os.DirEntry, err error) error {
@@ -37,6 +69,10 @@ func GetSubDirectories(parentDirectory string) []string {
 			return nil
 		}
 
+		if IsHidden(d.Name()) {
+			return skipHidden(d)
+		}
+
 		if d.IsDir() {
 			path = path[:len(path)-len(filepath.Ext(path))]
 			path = strings.Replace(path, parentDirectory, "", -1)
@@ -48,6 +84,19 @@ func GetSubDirectories(parentDirectory string) []string {
 	return result
 }
 
+// IsHidden reports whether a file or directory is hidden. Hidden entries of
+// the collections directory (such as environments) are not requests.
+func IsHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func skipHidden(d os.DirEntry) error {
+	if d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
 func CreateDirectory(directory string) {
 	err := os.MkdirAll(directory, 0755)
 	cobra.CheckErr(err)
@@ -65,3 +114,14 @@ func WriteStructToJSONFile(data interface{}, filepath string) {
 	_, err = file.Write(jsonData)
 	cobra.CheckErr(err)
 }
+
+// SafeFileName converts a name (e.g. of an imported request) into a valid
+// file name, which is not hidden.
+func SafeFileName(name string) string {
+	name = strings.TrimSpace(unsafeFileNameRegex.ReplaceAllString(name, "-"))
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
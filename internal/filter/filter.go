@@ -0,0 +1,566 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Filter is a parsed jq expression, e.g. ".data.items[0].id" or
+// ".items[] | select(.active) | {id, name}". It supports paths, iteration,
+// slices, pipes, commas, literals, array and object construction,
+// arithmetic, comparisons, and/or, "//", "if" conditionals and the most
+// common builtin functions. Variables, reduce and assignments are not
+// supported. Objects are decoded into maps, so unlike jq they are iterated,
+// listed by to_entries and printed with their keys sorted, not in the order
+// of the document.
+type Filter struct {
+	root node
+}
+
+// Parse parses the jq expression.
+func Parse(expression string) (*Filter, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parsePipe()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected("expected the end of the filter")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return &Filter{root: root}, nil
+}
+
+// Run returns every result of the filter over the decoded JSON value.
+func (f *Filter) Run(input interface{}) ([]interface{}, error) {
+	return f.root.eval(input)
+}
+
+// RunJSON returns every result of the filter over the JSON document. Numbers
+// keep their original representation, unless they are computed.
+func (f *Filter) RunJSON(document []byte) ([]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var input interface{}
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("body is not valid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("body is not valid JSON: unexpected data after the document")
+	}
+	return f.Run(input)
+}
+
+// node is an expression, which produces any number of results for an input.
+// On errors, the results produced before the error are returned with it.
+type node interface {
+	eval(input interface{}) ([]interface{}, error)
+}
+
+type identity struct{}
+
+func (identity) eval(input interface{}) ([]interface{}, error) {
+	return []interface{}{input}, nil
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (l literal) eval(interface{}) ([]interface{}, error) {
+	return []interface{}{l.value}, nil
+}
+
+// recurse is "..", which produces the input and every value inside it.
+type recurse struct{}
+
+func (recurse) eval(input interface{}) ([]interface{}, error) {
+	results := []interface{}{input}
+	children, err := iterateValue(input)
+	if err != nil {
+		return results, nil
+	}
+	for _, child := range children {
+		descendants, _ := recurse{}.eval(child)
+		results = append(results, descendants...)
+	}
+	return results, nil
+}
+
+type index struct {
+	target node
+	key    node
+}
+
+func (i index) eval(input interface{}) ([]interface{}, error) {
+	return product(i.target, i.key, input, indexValue)
+}
+
+type slice struct {
+	target node
+	// from and to are nil when they're omitted.
+	from, to node
+}
+
+func (s slice) eval(input interface{}) ([]interface{}, error) {
+	targets, err := s.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	froms, err := evalOrNull(s.from, input)
+	if err != nil {
+		return nil, err
+	}
+	tos, err := evalOrNull(s.to, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, target := range targets {
+		for _, from := range froms {
+			for _, to := range tos {
+				result, err := sliceValue(target, from, to)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+type iterate struct {
+	target node
+}
+
+func (i iterate) eval(input interface{}) ([]interface{}, error) {
+	targets, err := i.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, target := range targets {
+		values, err := iterateValue(target)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, values...)
+	}
+	return results, nil
+}
+
+// optional is "?", which ignores the errors of its body, keeping the
+// results produced before them.
+type optional struct {
+	body node
+}
+
+func (o optional) eval(input interface{}) ([]interface{}, error) {
+	results, _ := o.body.eval(input)
+	return results, nil
+}
+
+type pipe struct {
+	left, right node
+}
+
+func (p pipe) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := p.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, left := range lefts {
+		rights, err := p.right.eval(left)
+		results = append(results, rights...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+type comma struct {
+	left, right node
+}
+
+func (c comma) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := c.left.eval(input)
+	if err != nil {
+		return lefts, err
+	}
+	rights, err := c.right.eval(input)
+	return append(lefts, rights...), err
+}
+
+// alternative is "a // b", which produces the truthy results of a, or the
+// results of b if there are none.
+type alternative struct {
+	left, right node
+}
+
+func (a alternative) eval(input interface{}) ([]interface{}, error) {
+	lefts, _ := a.left.eval(input)
+
+	results := make([]interface{}, 0)
+	for _, left := range lefts {
+		if truthy(left) {
+			results = append(results, left)
+		}
+	}
+	if len(results) > 0 {
+		return results, nil
+	}
+	return a.right.eval(input)
+}
+
+type logical struct {
+	and         bool
+	left, right node
+}
+
+func (l logical) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := l.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, left := range lefts {
+		// The right side is only evaluated when it decides the result.
+		if truthy(left) != l.and {
+			results = append(results, !l.and)
+			continue
+		}
+
+		rights, err := l.right.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			results = append(results, truthy(right))
+		}
+	}
+	return results, nil
+}
+
+type binary struct {
+	operator    string
+	left, right node
+}
+
+func (b binary) eval(input interface{}) ([]interface{}, error) {
+	return product(b.left, b.right, input, func(left, right interface{}) (interface{}, error) {
+		return operate(b.operator, left, right)
+	})
+}
+
+type negate struct {
+	body node
+}
+
+func (n negate) eval(input interface{}) ([]interface{}, error) {
+	values, err := n.body.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be negated", describe(value))
+		}
+		results = append(results, -number)
+	}
+	return results, nil
+}
+
+// collect is "[...]", which collects the results of its body into an array.
+type collect struct {
+	// body is nil for an empty array.
+	body node
+}
+
+func (c collect) eval(input interface{}) ([]interface{}, error) {
+	if c.body == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+
+	values, err := c.body.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{append([]interface{}{}, values...)}, nil
+}
+
+type entry struct {
+	key, value node
+}
+
+// construct is "{...}", which produces an object for each combination of
+// the results of its entries.
+type construct struct {
+	entries []entry
+}
+
+func (c construct) eval(input interface{}) ([]interface{}, error) {
+	objects := []map[string]interface{}{{}}
+
+	for _, entry := range c.entries {
+		keys, err := entry.key.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(input)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]map[string]interface{}, 0)
+		for _, object := range objects {
+			for _, key := range keys {
+				name, ok := key.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, not %s", describe(key))
+				}
+				for _, value := range values {
+					copied := make(map[string]interface{}, len(object)+1)
+					for k, v := range object {
+						copied[k] = v
+					}
+					copied[name] = value
+					next = append(next, copied)
+				}
+			}
+		}
+		objects = next
+	}
+
+	results := make([]interface{}, 0, len(objects))
+	for _, object := range objects {
+		results = append(results, object)
+	}
+	return results, nil
+}
+
+type conditional struct {
+	condition, then, otherwise node
+}
+
+func (c conditional) eval(input interface{}) ([]interface{}, error) {
+	conditions, err := c.condition.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, condition := range conditions {
+		branch := c.otherwise
+		if truthy(condition) {
+			branch = c.then
+		}
+		values, err := branch.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, values...)
+	}
+	return results, nil
+}
+
+type call struct {
+	name string
+	args []node
+}
+
+func (c call) eval(input interface{}) ([]interface{}, error) {
+	return functions[functionKey(c.name, len(c.args))](input, c.args)
+}
+
+// product applies the operation to every combination of the results of the
+// left and right expressions.
+func product(left, right node, input interface{}, operation func(left, right interface{}) (interface{}, error)) ([]interface{}, error) {
+	lefts, err := left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, len(lefts)*len(rights))
+	for _, r := range rights {
+		for _, l := range lefts {
+			result, err := operation(l, r)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func evalOrNull(n node, input interface{}) ([]interface{}, error) {
+	if n == nil {
+		return []interface{}{nil}, nil
+	}
+	return n.eval(input)
+}
+
+func indexValue(target, key interface{}) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+
+	switch typed := target.(type) {
+	case map[string]interface{}:
+		if name, ok := key.(string); ok {
+			return typed[name], nil
+		}
+	case []interface{}:
+		if number, ok := toNumber(key); ok {
+			i := int(math.Floor(number))
+			if i < 0 {
+				i += len(typed)
+			}
+			if i < 0 || i >= len(typed) {
+				return nil, nil
+			}
+			return typed[i], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(target), describe(key))
+}
+
+func sliceValue(target, from, to interface{}) (interface{}, error) {
+	var length int
+	switch typed := target.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		length = len(typed)
+	case string:
+		length = len([]rune(typed))
+	default:
+		return nil, fmt.Errorf("cannot slice %s", typeName(target))
+	}
+
+	bound := func(value interface{}, fallback int) (int, error) {
+		if value == nil {
+			return fallback, nil
+		}
+		number, ok := toNumber(value)
+		if !ok {
+			return 0, fmt.Errorf("slice indexes must be numbers, not %s", describe(value))
+		}
+		i := int(math.Floor(number))
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length), nil
+	}
+
+	start, err := bound(from, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, length)
+	if err != nil {
+		return nil, err
+	}
+	end = max(start, end)
+
+	if text, ok := target.(string); ok {
+		return string([]rune(text)[start:end]), nil
+	}
+	return target.([]interface{})[start:end], nil
+}
+
+// iterateValue returns the elements of an array, or the values of an object
+// sorted by their keys.
+func iterateValue(value interface{}) ([]interface{}, error) {
+	switch typed := value.(type) {
+	case []interface{}:
+		return typed, nil
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(typed))
+		for _, key := range sortedKeys(typed) {
+			values = append(values, typed[key])
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", describe(value))
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func truthy(value interface{}) bool {
+	return value != nil && value != false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case json.Number:
+		number, err := typed.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// describe describes the value for errors, e.g. `string ("abc")`.
+func describe(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if value == nil || err != nil {
+		return typeName(value)
+	}
+
+	text := string(encoded)
+	if len(text) > 30 {
+		text = text[:27] + "..."
+	}
+	return fmt.Sprintf("%s (%s)", typeName(value), strings.TrimSpace(text))
+}
@@ -0,0 +1,255 @@
+package filter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const document = `{
+	"id": 7,
+	"name": "httpmate",
+	"tags": ["cli", "http", "json"],
+	"owner": {"login": "joao", "site": null},
+	"items": [
+		{"id": 1, "name": "b", "active": true, "price": 10.5},
+		{"id": 2, "name": "a", "active": false, "price": 3},
+		{"id": 3, "name": "c", "active": true, "price": 7}
+	]
+}`
+
+// runFilter runs the expression over the JSON input, and returns its results
+// encoded as JSON.
+func runFilter(t *testing.T, expression, input string) ([]string, error) {
+	t.Helper()
+
+	f, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	results, err := f.RunJSON([]byte(input))
+
+	encoded := make([]string, 0, len(results))
+	for _, result := range results {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			t.Fatalf("%s: %s", expression, marshalErr)
+		}
+		encoded = append(encoded, string(data))
+	}
+	return encoded, err
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		input      string
+		expected   []string
+	}{
+		// Paths
+		{expression: ".", input: `{"a":1}`, expected: []string{`{"a":1}`}},
+		{expression: ".name", input: document, expected: []string{`"httpmate"`}},
+		{expression: ".owner.login", input: document, expected: []string{`"joao"`}},
+		{expression: `.owner["login"]`, input: document, expected: []string{`"joao"`}},
+		{expression: `."id"`, input: document, expected: []string{`7`}},
+		{expression: ".missing.deeper", input: document, expected: []string{`null`}},
+		{expression: ".items[0].id", input: document, expected: []string{`1`}},
+		{expression: ".items[-1].name", input: document, expected: []string{`"c"`}},
+		{expression: ".items[10]", input: document, expected: []string{`null`}},
+		{expression: ".tags[]", input: document, expected: []string{`"cli"`, `"http"`, `"json"`}},
+		{expression: ".items[].id", input: document, expected: []string{`1`, `2`, `3`}},
+		{expression: ".owner[]", input: document, expected: []string{`"joao"`, `null`}},
+		{expression: "..|numbers", input: `{"a":[1,{"b":2}]}`, expected: []string{`1`, `2`}},
+		{expression: ".a?", input: `[1]`, expected: []string{}},
+		{expression: ".[]?", input: `3`, expected: []string{}},
+		{expression: "1.5", input: `null`, expected: []string{`1.5`}},
+
+		// Slices
+		{expression: ".tags[1:]", input: document, expected: []string{`["http","json"]`}},
+		{expression: ".tags[:2]", input: document, expected: []string{`["cli","http"]`}},
+		{expression: ".tags[-2:]", input: document, expected: []string{`["http","json"]`}},
+		{expression: ".tags[2:1]", input: document, expected: []string{`[]`}},
+		{expression: ".name[0:4]", input: document, expected: []string{`"http"`}},
+		{expression: `.[1:3]`, input: `"héllo"`, expected: []string{`"él"`}},
+		{expression: ".missing[1:]", input: document, expected: []string{`null`}},
+
+		// Pipes, commas and select/map
+		{expression: ".owner | .login", input: document, expected: []string{`"joao"`}},
+		{expression: ".id, .name", input: document, expected: []string{`7`, `"httpmate"`}},
+		{expression: ".items[] | select(.active) | .name", input: document, expected: []string{`"b"`, `"c"`}},
+		{expression: ".items[] | select(.price > 5 and .id != 3) | .id", input: document, expected: []string{`1`}},
+		{expression: ".items | map(.id * 10)", input: document, expected: []string{`[10,20,30]`}},
+		{expression: ".items | map(select(.active | not)) | length", input: document, expected: []string{`1`}},
+		{expression: "[.items[] | .name]", input: document, expected: []string{`["b","a","c"]`}},
+		{expression: "[.[] | select(. > 1)]", input: `[1,2,3]`, expected: []string{`[2,3]`}},
+		{expression: "[.[] | empty]", input: `[1,2]`, expected: []string{`[]`}},
+
+		// Alternatives
+		{expression: ".owner.site // \"none\"", input: document, expected: []string{`"none"`}},
+		{expression: ".owner.login // \"none\"", input: document, expected: []string{`"joao"`}},
+		{expression: ".a // .b // 3", input: `{"a":false,"b":null}`, expected: []string{`3`}},
+		{expression: "(.[] | select(. > 5)) // 0", input: `[1,7,9]`, expected: []string{`7`, `9`}},
+		{expression: ".x // empty", input: `{}`, expected: []string{}},
+
+		// Construction
+		{expression: "{id, name}", input: document, expected: []string{`{"id":7,"name":"httpmate"}`}},
+		{expression: `{login: .owner.login, "count": (.tags | length)}`, input: document, expected: []string{`{"count":3,"login":"joao"}`}},
+		{expression: "{(.name): .id}", input: document, expected: []string{`{"httpmate":7}`}},
+		{expression: "{id: .items[].id}", input: document, expected: []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}},
+		{expression: "[.id, .tags[0]]", input: document, expected: []string{`[7,"cli"]`}},
+		{expression: "[]", input: `null`, expected: []string{`[]`}},
+		{expression: "{}", input: `null`, expected: []string{`{}`}},
+
+		// Arithmetic and comparisons
+		{expression: ".id + 1", input: document, expected: []string{`8`}},
+		{expression: ".price * 2 - 1", input: `{"price":10.5}`, expected: []string{`20`}},
+		{expression: "10 / 4", input: `null`, expected: []string{`2.5`}},
+		{expression: "10 % 4", input: `null`, expected: []string{`2`}},
+		{expression: "-.id", input: document, expected: []string{`-7`}},
+		{expression: `.name + "!"`, input: document, expected: []string{`"httpmate!"`}},
+		{expression: ".tags + [\"go\"]", input: document, expected: []string{`["cli","http","json","go"]`}},
+		{expression: "[1,2,2,3] - [2]", input: `null`, expected: []string{`[1,3]`}},
+		{expression: `{"a":1} + {"b":2}`, input: `null`, expected: []string{`{"a":1,"b":2}`}},
+		{expression: "null + 1", input: `null`, expected: []string{`1`}},
+		{expression: `"a,b" / ","`, input: `null`, expected: []string{`["a","b"]`}},
+		{expression: ".id == 7, .id < 7, .id >= 7", input: document, expected: []string{`true`, `false`, `true`}},
+		{expression: `"a" < "b", [1] > null`, input: `null`, expected: []string{`true`, `true`}},
+		{expression: "true or .a.b, false and .a.b", input: `[1]`, expected: []string{`true`, `false`}},
+		{expression: "if .id > 5 then \"big\" elif .id > 1 then \"medium\" else \"small\" end", input: document, expected: []string{`"big"`}},
+		{expression: "if .missing then 1 end", input: document, expected: []string{`{"id":7,"items":[{"active":true,"id":1,"name":"b","price":10.5},{"active":false,"id":2,"name":"a","price":3},{"active":true,"id":3,"name":"c","price":7}],"name":"httpmate","owner":{"login":"joao","site":null},"tags":["cli","http","json"]}`}},
+
+		// Builtins
+		{expression: ".tags | length", input: document, expected: []string{`3`}},
+		{expression: ".name | length", input: document, expected: []string{`8`}},
+		{expression: ".owner | length", input: document, expected: []string{`2`}},
+		{expression: "null | length", input: `null`, expected: []string{`0`}},
+		{expression: "-5 | length", input: `null`, expected: []string{`5`}},
+		{expression: ".owner | keys", input: document, expected: []string{`["login","site"]`}},
+		{expression: ".tags | keys", input: document, expected: []string{`[0,1,2]`}},
+		{expression: `has("owner"), has("nope")`, input: document, expected: []string{`true`, `false`}},
+		{expression: ".tags | has(1)", input: document, expected: []string{`true`}},
+		{expression: "map(type)", input: `[null,true,1,"a",[],{}]`, expected: []string{`["null","boolean","number","string","array","object"]`}},
+		{expression: ".items | sort_by(.name) | map(.id)", input: document, expected: []string{`[2,1,3]`}},
+		{expression: ".items | sort_by(.price) | map(.id)", input: document, expected: []string{`[2,3,1]`}},
+		{expression: ".items | group_by(.active) | map(length)", input: document, expected: []string{`[1,2]`}},
+		{expression: ".items | unique_by(.active) | map(.id)", input: document, expected: []string{`[2,1]`}},
+		{expression: "sort", input: `[3,"a",null,1,true,false]`, expected: []string{`[null,false,true,1,3,"a"]`}},
+		{expression: "unique", input: `[2,1,2,1]`, expected: []string{`[1,2]`}},
+		{expression: "min, max", input: `[3,1,2]`, expected: []string{`1`, `3`}},
+		{expression: "min", input: `[]`, expected: []string{`null`}},
+		{expression: "add", input: `[1,2,3]`, expected: []string{`6`}},
+		{expression: "add", input: `["a","b"]`, expected: []string{`"ab"`}},
+		{expression: "add", input: `[]`, expected: []string{`null`}},
+		{expression: "any, all", input: `[true,false]`, expected: []string{`true`, `false`}},
+		{expression: "first, last", input: `[1,2,3]`, expected: []string{`1`, `3`}},
+		{expression: "first(.[] | select(. > 1))", input: `[1,2,3]`, expected: []string{`2`}},
+		{expression: "reverse", input: `[1,2,3]`, expected: []string{`[3,2,1]`}},
+		{expression: "reverse", input: `"abc"`, expected: []string{`"cba"`}},
+		{expression: `.tags | join(", ")`, input: document, expected: []string{`"cli, http, json"`}},
+		{expression: `join("-")`, input: `["a",1,null,true]`, expected: []string{`"a-1--true"`}},
+		{expression: `split("-")`, input: `"a-b-c"`, expected: []string{`["a","b","c"]`}},
+		{expression: `test("^http")`, input: `"httpmate"`, expected: []string{`true`}},
+		{expression: `startswith("http"), endswith("mate")`, input: `"httpmate"`, expected: []string{`true`, `true`}},
+		{expression: `ltrimstr("http"), rtrimstr("mate")`, input: `"httpmate"`, expected: []string{`"mate"`, `"http"`}},
+		{expression: "ascii_upcase, ascii_downcase", input: `"MiXed"`, expected: []string{`"MIXED"`, `"mixed"`}},
+		{expression: `contains("mate"), contains("go")`, input: `"httpmate"`, expected: []string{`true`, `false`}},
+		{expression: `contains(["cl"])`, input: `["cli","http"]`, expected: []string{`true`}},
+		{expression: `contains({"a":{"b":1}})`, input: `{"a":{"b":1,"c":2}}`, expected: []string{`true`}},
+		{expression: "tostring", input: `{"a":1}`, expected: []string{`"{\"a\":1}"`}},
+		{expression: "map(tostring)", input: `[1,"a",null]`, expected: []string{`["1","a","null"]`}},
+		{expression: "tonumber", input: `"12.5"`, expected: []string{`12.5`}},
+		{expression: "tojson", input: `[1,"a"]`, expected: []string{`"[1,\"a\"]"`}},
+		{expression: "fromjson | .a", input: `"{\"a\":[1]}"`, expected: []string{`[1]`}},
+		{expression: "floor, ceil, round", input: `2.5`, expected: []string{`2`, `3`, `3`}},
+		{expression: ".owner | to_entries", input: document, expected: []string{`[{"key":"login","value":"joao"},{"key":"site","value":null}]`}},
+		{expression: "from_entries", input: `[{"key":"a","value":1},{"name":"b","value":2},{"k":"c","v":3}]`, expected: []string{`{"a":1,"b":2,"c":3}`}},
+		{expression: "with_entries({key, value: (.value + 1)})", input: `{"a":1,"b":2}`, expected: []string{`{"a":2,"b":3}`}},
+		{expression: "with_entries(select(.value > 1))", input: `{"a":1,"b":2}`, expected: []string{`{"b":2}`}},
+		{expression: "[.[] | numbers]", input: `[1,"a",null,2]`, expected: []string{`[1,2]`}},
+		{expression: "[.[] | strings], [.[] | nulls], [.[] | values]", input: `[1,"a",null]`, expected: []string{`["a"]`, `[null]`, `[1,"a"]`}},
+		{expression: "[.[] | iterables], [.[] | scalars]", input: `[[],{},1]`, expected: []string{`[[],{}]`, `[1]`}},
+
+		// Objects are iterated and constructed with their keys sorted.
+		{expression: ".[]", input: `{"id":0,"b":1,"a":2}`, expected: []string{`2`, `1`, `0`}},
+		{expression: "{b, a}", input: `{"b":1,"a":2}`, expected: []string{`{"a":2,"b":1}`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			results, err := runFilter(t, test.expression, test.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(results, test.expected) {
+				t.Errorf("over %s\nexpected %v\ngot      %v", test.input, test.expected, results)
+			}
+		})
+	}
+}
+
+func TestFilterNumbersKeepTheirRepresentation(t *testing.T) {
+	results, err := runFilter(t, ".big, .small", `{"big": 12345678901234567890, "small": 1.10}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{`12345678901234567890`, `1.10`}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		input      string
+		expected   string
+		// partial are the results produced before the error.
+		partial []string
+	}{
+		// Parsing
+		{expression: ".a |", input: `{}`, expected: "invalid filter: unexpected end of the filter, expected a value"},
+		{expression: ".a[", input: `{}`, expected: "invalid filter: unexpected end of the filter, expected a value"},
+		{expression: ".a )", input: `{}`, expected: `invalid filter: unexpected ")" at position 3, expected the end of the filter`},
+		{expression: "$x", input: `{}`, expected: "invalid filter: variables are not supported, at position 0"},
+		{expression: `"abc`, input: `{}`, expected: "invalid filter: unterminated string at position 0"},
+		{expression: `"\(.a)"`, input: `{}`, expected: "invalid filter: string interpolation is not supported, at position 1"},
+		{expression: "nope(1)", input: `{}`, expected: "invalid filter: unknown function nope/1"},
+		{expression: ".a ; .b", input: `{}`, expected: `invalid filter: unexpected ";" at position 3, expected the end of the filter`},
+
+		// Evaluation
+		{expression: ".a", input: `[1]`, expected: `cannot index array with string ("a")`},
+		{expression: ".[0]", input: `{"a":1}`, expected: `cannot index object with number (0)`},
+		{expression: ".[]", input: `5`, expected: `cannot iterate over number (5)`},
+		{expression: ".[1:]", input: `{}`, expected: `cannot slice object`},
+		{expression: `.[] | .a`, input: `[{"a":1},2]`, expected: `cannot index number with string ("a")`, partial: []string{`1`}},
+		{expression: `contains(["cli"])`, input: `"httpmate"`, expected: `string ("httpmate") and array (["cli"]) cannot have their containment checked`},
+		{expression: `.a + .b`, input: `{"a":1,"b":"x"}`, expected: `number (1) and string ("x") cannot be added`},
+		{expression: "1 / 0", input: `null`, expected: `number (1) and number (0) cannot be divided because the divisor is zero`},
+		{expression: "{(.a): 1}", input: `{"a":1}`, expected: `object keys must be strings, not number (1)`},
+		{expression: "length", input: `true`, expected: `boolean (true) has no length`},
+		{expression: "keys", input: `"a"`, expected: `string ("a") has no keys`},
+		{expression: "sort", input: `{}`, expected: `object ({}) cannot be sorted, as it is not an array`},
+		{expression: `join(",")`, input: `[[1]]`, expected: `array ([1]) cannot be joined`},
+		{expression: "tonumber", input: `"abc"`, expected: `string ("abc") cannot be parsed as a number`},
+		{expression: `test("(")`, input: `"a"`, expected: "invalid regex \"(\": error parsing regexp: missing closing ): `(`"},
+		{expression: "to_entries", input: `[]`, expected: `array ([]) has no entries, as it is not an object`},
+		{expression: "-.", input: `"a"`, expected: `string ("a") cannot be negated`},
+		{expression: ".", input: `{"a":`, expected: "body is not valid JSON: unexpected EOF"},
+		{expression: ".", input: `{} {}`, expected: "body is not valid JSON: unexpected data after the document"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			results, err := runFilter(t, test.expression, test.input)
+			if err == nil {
+				t.Fatalf("expected an error, got %v", results)
+			}
+			if err.Error() != test.expected {
+				t.Errorf("expected the error\n%s\ngot\n%s", test.expected, err)
+			}
+			if len(test.partial) > 0 && !reflect.DeepEqual(results, test.partial) {
+				t.Errorf("expected the results %v before the error, got %v", test.partial, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,801 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// function is a builtin function, which gets the expressions of its
+// arguments so it can evaluate them over any input (e.g. map(f)).
+type function func(input interface{}, args []node) ([]interface{}, error)
+
+// functions are the builtin functions, by name and arity (e.g. "map/1").
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"empty/0":          func(interface{}, []node) ([]interface{}, error) { return nil, nil },
+		"not/0":            simple(func(input interface{}) (interface{}, error) { return !truthy(input), nil }),
+		"type/0":           simple(func(input interface{}) (interface{}, error) { return typeName(input), nil }),
+		"length/0":         simple(length),
+		"keys/0":           simple(keys),
+		"add/0":            simple(add),
+		"any/0":            simple(func(input interface{}) (interface{}, error) { return anyAll(input, true) }),
+		"all/0":            simple(func(input interface{}) (interface{}, error) { return anyAll(input, false) }),
+		"first/0":          simple(func(input interface{}) (interface{}, error) { return indexValue(input, 0.0) }),
+		"last/0":           simple(func(input interface{}) (interface{}, error) { return indexValue(input, -1.0) }),
+		"reverse/0":        simple(reverse),
+		"sort/0":           simple(func(input interface{}) (interface{}, error) { return sortBy(input, nil) }),
+		"unique/0":         simple(func(input interface{}) (interface{}, error) { return uniqueBy(input, nil) }),
+		"min/0":            simple(func(input interface{}) (interface{}, error) { return extreme(input, -1) }),
+		"max/0":            simple(func(input interface{}) (interface{}, error) { return extreme(input, 1) }),
+		"tostring/0":       simple(tostring),
+		"tonumber/0":       simple(tonumber),
+		"tojson/0":         simple(tojson),
+		"fromjson/0":       simple(fromjson),
+		"ascii_downcase/0": simple(stringFunction(strings.ToLower)),
+		"ascii_upcase/0":   simple(stringFunction(strings.ToUpper)),
+		"to_entries/0":     simple(toEntries),
+		"from_entries/0":   simple(fromEntries),
+		"floor/0":          simple(numberFunction(math.Floor)),
+		"ceil/0":           simple(numberFunction(math.Ceil)),
+		"round/0":          simple(numberFunction(math.Round)),
+		"has/1":            withArg(has),
+		"contains/1":       withArg(func(input, arg interface{}) (interface{}, error) { return contains(input, arg) }),
+		"join/1":           withArg(join),
+		"split/1":          withArg(func(input, arg interface{}) (interface{}, error) { return operate("/", input, arg) }),
+		"test/1":           withArg(test),
+		"startswith/1":     withArg(stringPredicate(strings.HasPrefix)),
+		"endswith/1":       withArg(stringPredicate(strings.HasSuffix)),
+		"ltrimstr/1":       withArg(trim(strings.TrimPrefix)),
+		"rtrimstr/1":       withArg(trim(strings.TrimSuffix)),
+		"map/1":            mapValues,
+		"select/1":         selectValues,
+		"first/1":          firstResult,
+		"sort_by/1":        byKey(sortBy),
+		"unique_by/1":      byKey(uniqueBy),
+		"group_by/1":       byKey(groupBy),
+		"with_entries/1":   withEntries,
+		"values/0":         selectType(func(value interface{}) bool { return value != nil }),
+		"nulls/0":          selectType(func(value interface{}) bool { return value == nil }),
+		"booleans/0":       selectType(isType("boolean")),
+		"numbers/0":        selectType(isType("number")),
+		"strings/0":        selectType(isType("string")),
+		"arrays/0":         selectType(isType("array")),
+		"objects/0":        selectType(isType("object")),
+		"iterables/0":      selectType(func(value interface{}) bool { return isType("array")(value) || isType("object")(value) }),
+		"scalars/0":        selectType(func(value interface{}) bool { return !isType("array")(value) && !isType("object")(value) }),
+	}
+}
+
+func functionKey(name string, arity int) string {
+	return name + "/" + strconv.Itoa(arity)
+}
+
+// simple makes a function without arguments, with a single result.
+func simple(fn func(input interface{}) (interface{}, error)) function {
+	return func(input interface{}, _ []node) ([]interface{}, error) {
+		result, err := fn(input)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{result}, nil
+	}
+}
+
+// withArg makes a function with a single argument, which is applied to each
+// value of the argument.
+func withArg(fn func(input, arg interface{}) (interface{}, error)) function {
+	return func(input interface{}, args []node) ([]interface{}, error) {
+		values, err := args[0].eval(input)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			result, err := fn(input, value)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	}
+}
+
+// byKey makes a function of an array and the keys of its elements, which are
+// the results of the argument over each element (e.g. sort_by(.name)).
+func byKey(fn func(input interface{}, keys []interface{}) (interface{}, error)) function {
+	return func(input interface{}, args []node) ([]interface{}, error) {
+		array, ok := input.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an array", describe(input))
+		}
+
+		keys := make([]interface{}, 0, len(array))
+		for _, element := range array {
+			key, err := args[0].eval(element)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+
+		result, err := fn(input, keys)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{result}, nil
+	}
+}
+
+// selectType makes a function which produces its input only if it matches,
+// e.g. numbers.
+func selectType(matches func(value interface{}) bool) function {
+	return func(input interface{}, _ []node) ([]interface{}, error) {
+		if !matches(input) {
+			return nil, nil
+		}
+		return []interface{}{input}, nil
+	}
+}
+
+func isType(name string) func(value interface{}) bool {
+	return func(value interface{}) bool {
+		return typeName(value) == name
+	}
+}
+
+func mapValues(input interface{}, args []node) ([]interface{}, error) {
+	values, err := iterateValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		results, err := args[0].eval(value)
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, results...)
+	}
+	return []interface{}{mapped}, nil
+}
+
+func selectValues(input interface{}, args []node) ([]interface{}, error) {
+	conditions, err := args[0].eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, condition := range conditions {
+		if truthy(condition) {
+			results = append(results, input)
+		}
+	}
+	return results, nil
+}
+
+func firstResult(input interface{}, args []node) ([]interface{}, error) {
+	results, err := args[0].eval(input)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[:1], nil
+}
+
+func withEntries(input interface{}, args []node) ([]interface{}, error) {
+	entries, err := toEntries(input)
+	if err != nil {
+		return nil, err
+	}
+	mapped, err := mapValues(entries, args)
+	if err != nil {
+		return nil, err
+	}
+	object, err := fromEntries(mapped[0])
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{object}, nil
+}
+
+func length(input interface{}) (interface{}, error) {
+	switch typed := input.(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(len([]rune(typed))), nil
+	case []interface{}:
+		return float64(len(typed)), nil
+	case map[string]interface{}:
+		return float64(len(typed)), nil
+	}
+	if number, ok := toNumber(input); ok {
+		return math.Abs(number), nil
+	}
+	return nil, fmt.Errorf("%s has no length", describe(input))
+}
+
+func keys(input interface{}) (interface{}, error) {
+	switch typed := input.(type) {
+	case map[string]interface{}:
+		result := make([]interface{}, 0, len(typed))
+		for _, key := range sortedKeys(typed) {
+			result = append(result, key)
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(typed))
+		for i := range typed {
+			result = append(result, float64(i))
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", describe(input))
+}
+
+func has(input, key interface{}) (interface{}, error) {
+	switch typed := input.(type) {
+	case map[string]interface{}:
+		if name, ok := key.(string); ok {
+			_, found := typed[name]
+			return found, nil
+		}
+	case []interface{}:
+		if number, ok := toNumber(key); ok {
+			return number >= 0 && int(number) < len(typed), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot check whether %s has a key %s", typeName(input), describe(key))
+}
+
+func add(input interface{}) (interface{}, error) {
+	values, err := iterateValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var sum interface{}
+	for _, value := range values {
+		if sum, err = operate("+", sum, value); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+// anyAll reports whether any (or all, if any is false) of the elements are
+// truthy.
+func anyAll(input interface{}, any bool) (interface{}, error) {
+	values, err := iterateValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		if truthy(value) == any {
+			return any, nil
+		}
+	}
+	return !any, nil
+}
+
+func reverse(input interface{}) (interface{}, error) {
+	switch typed := input.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case string:
+		runes := []rune(typed)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, value := range typed {
+			result[len(typed)-1-i] = value
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("cannot reverse %s", describe(input))
+}
+
+// sortBy sorts the array by the keys of its elements, or by the elements
+// themselves if keys is nil.
+func sortBy(input interface{}, keys []interface{}) (interface{}, error) {
+	array, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be sorted, as it is not an array", describe(input))
+	}
+	if keys == nil {
+		keys = array
+	}
+
+	order := make([]int, len(array))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return compare(keys[order[i]], keys[order[j]]) < 0
+	})
+
+	result := make([]interface{}, 0, len(array))
+	for _, i := range order {
+		result = append(result, array[i])
+	}
+	return result, nil
+}
+
+func uniqueBy(input interface{}, keys []interface{}) (interface{}, error) {
+	groups, err := groupBy(input, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for _, group := range groups.([]interface{}) {
+		result = append(result, group.([]interface{})[0])
+	}
+	return result, nil
+}
+
+func groupBy(input interface{}, keys []interface{}) (interface{}, error) {
+	array, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be grouped, as it is not an array", describe(input))
+	}
+	if keys == nil {
+		keys = array
+	}
+
+	order := make([]int, len(array))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return compare(keys[order[i]], keys[order[j]]) < 0
+	})
+
+	groups := make([]interface{}, 0)
+	for n, i := range order {
+		if n == 0 || compare(keys[order[n-1]], keys[i]) != 0 {
+			groups = append(groups, []interface{}{})
+		}
+		last := len(groups) - 1
+		groups[last] = append(groups[last].([]interface{}), array[i])
+	}
+	return groups, nil
+}
+
+// extreme returns the smallest (sign -1) or largest (sign 1) element.
+func extreme(input interface{}, sign int) (interface{}, error) {
+	array, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", describe(input))
+	}
+
+	var result interface{}
+	for i, value := range array {
+		if i == 0 || compare(value, result)*sign > 0 {
+			result = value
+		}
+	}
+	return result, nil
+}
+
+func tostring(input interface{}) (interface{}, error) {
+	if text, ok := input.(string); ok {
+		return text, nil
+	}
+	return tojson(input)
+}
+
+func tonumber(input interface{}) (interface{}, error) {
+	if _, ok := toNumber(input); ok {
+		return input, nil
+	}
+	if text, ok := input.(string); ok {
+		if number, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			return number, nil
+		}
+	}
+	return nil, fmt.Errorf("%s cannot be parsed as a number", describe(input))
+}
+
+func tojson(input interface{}) (interface{}, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(input); err != nil {
+		return nil, err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func fromjson(input interface{}) (interface{}, error) {
+	text, ok := input.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be parsed as JSON, as it is not a string", describe(input))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%s cannot be parsed as JSON: %w", describe(input), err)
+	}
+	return value, nil
+}
+
+func stringFunction(fn func(string) string) func(interface{}) (interface{}, error) {
+	return func(input interface{}) (interface{}, error) {
+		text, ok := input.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string", describe(input))
+		}
+		return fn(text), nil
+	}
+}
+
+func numberFunction(fn func(float64) float64) func(interface{}) (interface{}, error) {
+	return func(input interface{}) (interface{}, error) {
+		number, ok := toNumber(input)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a number", describe(input))
+		}
+		return fn(number), nil
+	}
+}
+
+func stringPredicate(fn func(text, arg string) bool) func(input, arg interface{}) (interface{}, error) {
+	return func(input, arg interface{}) (interface{}, error) {
+		text, ok := input.(string)
+		argText, argOk := arg.(string)
+		if !ok || !argOk {
+			return nil, fmt.Errorf("%s and %s must be strings", describe(input), describe(arg))
+		}
+		return fn(text, argText), nil
+	}
+}
+
+// trim returns the input as it is if it's not a string, like jq.
+func trim(fn func(text, arg string) string) func(input, arg interface{}) (interface{}, error) {
+	return func(input, arg interface{}) (interface{}, error) {
+		text, ok := input.(string)
+		argText, argOk := arg.(string)
+		if !ok || !argOk {
+			return input, nil
+		}
+		return fn(text, argText), nil
+	}
+}
+
+func join(input, separator interface{}) (interface{}, error) {
+	array, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be joined, as it is not an array", describe(input))
+	}
+	sep, ok := separator.(string)
+	if !ok {
+		return nil, fmt.Errorf("the separator %s is not a string", describe(separator))
+	}
+
+	parts := make([]string, 0, len(array))
+	for _, element := range array {
+		switch typed := element.(type) {
+		case nil:
+			parts = append(parts, "")
+		case string:
+			parts = append(parts, typed)
+		case bool, float64, json.Number:
+			text, _ := tojson(typed)
+			parts = append(parts, text.(string))
+		default:
+			return nil, fmt.Errorf("%s cannot be joined", describe(element))
+		}
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func test(input, pattern interface{}) (interface{}, error) {
+	text, ok := input.(string)
+	expression, patternOk := pattern.(string)
+	if !ok || !patternOk {
+		return nil, fmt.Errorf("%s cannot be matched against %s", describe(input), describe(pattern))
+	}
+
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", expression, err)
+	}
+	return re.MatchString(text), nil
+}
+
+// contains reports whether b is contained in a: substrings of strings, and
+// recursively the elements of arrays and the values of objects.
+func contains(a, b interface{}) (bool, error) {
+	if typeName(a) != typeName(b) {
+		return false, fmt.Errorf("%s and %s cannot have their containment checked", describe(a), describe(b))
+	}
+
+	switch typedA := a.(type) {
+	case string:
+		return strings.Contains(typedA, b.(string)), nil
+	case []interface{}:
+		for _, elementB := range b.([]interface{}) {
+			found := false
+			for _, elementA := range typedA {
+				if ok, _ := contains(elementA, elementB); ok {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false, nil
+			}
+		}
+		return true, nil
+	case map[string]interface{}:
+		for key, valueB := range b.(map[string]interface{}) {
+			valueA, found := typedA[key]
+			if !found {
+				return false, nil
+			}
+			if ok, err := contains(valueA, valueB); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return compare(a, b) == 0, nil
+}
+
+func toEntries(input interface{}) (interface{}, error) {
+	object, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no entries, as it is not an object", describe(input))
+	}
+
+	entries := make([]interface{}, 0, len(object))
+	for _, key := range sortedKeys(object) {
+		entries = append(entries, map[string]interface{}{"key": key, "value": object[key]})
+	}
+	return entries, nil
+}
+
+func fromEntries(input interface{}) (interface{}, error) {
+	entries, err := iterateValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]interface{}{}
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the entry %s is not an object", describe(entry))
+		}
+
+		var key interface{}
+		for _, name := range []string{"key", "k", "name", "Name", "Key", "K"} {
+			if value, ok := fields[name]; ok && value != nil {
+				key = value
+				break
+			}
+		}
+		var value interface{}
+		for _, name := range []string{"value", "v", "Value", "V"} {
+			if found, ok := fields[name]; ok {
+				value = found
+				break
+			}
+		}
+
+		switch typed := key.(type) {
+		case string:
+			object[typed] = value
+		case bool, float64, json.Number:
+			text, _ := tojson(typed)
+			object[text.(string)] = value
+		default:
+			return nil, fmt.Errorf("the entry %s has no valid key", describe(entry))
+		}
+	}
+	return object, nil
+}
+
+// operate applies the arithmetic or comparison operator.
+func operate(operator string, left, right interface{}) (interface{}, error) {
+	switch operator {
+	case "==":
+		return compare(left, right) == 0, nil
+	case "!=":
+		return compare(left, right) != 0, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	}
+
+	leftNumber, leftIsNumber := toNumber(left)
+	rightNumber, rightIsNumber := toNumber(right)
+	bothNumbers := leftIsNumber && rightIsNumber
+
+	switch operator {
+	case "+":
+		switch {
+		case left == nil:
+			return right, nil
+		case right == nil:
+			return left, nil
+		case bothNumbers:
+			return leftNumber + rightNumber, nil
+		}
+		switch typed := left.(type) {
+		case string:
+			if text, ok := right.(string); ok {
+				return typed + text, nil
+			}
+		case []interface{}:
+			if array, ok := right.([]interface{}); ok {
+				return append(append([]interface{}{}, typed...), array...), nil
+			}
+		case map[string]interface{}:
+			if object, ok := right.(map[string]interface{}); ok {
+				merged := make(map[string]interface{}, len(typed)+len(object))
+				for key, value := range typed {
+					merged[key] = value
+				}
+				for key, value := range object {
+					merged[key] = value
+				}
+				return merged, nil
+			}
+		}
+		return nil, fmt.Errorf("%s and %s cannot be added", describe(left), describe(right))
+	case "-":
+		if bothNumbers {
+			return leftNumber - rightNumber, nil
+		}
+		leftArray, leftOk := left.([]interface{})
+		rightArray, rightOk := right.([]interface{})
+		if leftOk && rightOk {
+			result := make([]interface{}, 0, len(leftArray))
+			for _, element := range leftArray {
+				removed := false
+				for _, toRemove := range rightArray {
+					if compare(element, toRemove) == 0 {
+						removed = true
+						break
+					}
+				}
+				if !removed {
+					result = append(result, element)
+				}
+			}
+			return result, nil
+		}
+		return nil, fmt.Errorf("%s and %s cannot be subtracted", describe(left), describe(right))
+	case "*":
+		if bothNumbers {
+			return leftNumber * rightNumber, nil
+		}
+		return nil, fmt.Errorf("%s and %s cannot be multiplied", describe(left), describe(right))
+	case "/":
+		if bothNumbers {
+			if rightNumber == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+			}
+			return leftNumber / rightNumber, nil
+		}
+		text, leftOk := left.(string)
+		separator, rightOk := right.(string)
+		if leftOk && rightOk {
+			parts := make([]interface{}, 0)
+			if text != "" {
+				for _, part := range strings.Split(text, separator) {
+					parts = append(parts, part)
+				}
+			}
+			return parts, nil
+		}
+		return nil, fmt.Errorf("%s and %s cannot be divided", describe(left), describe(right))
+	case "%":
+		if bothNumbers {
+			divisor := int(rightNumber)
+			if divisor == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+			}
+			return float64(int(leftNumber) % divisor), nil
+		}
+		return nil, fmt.Errorf("%s and %s cannot be divided", describe(left), describe(right))
+	}
+
+	return nil, fmt.Errorf("unknown operator %q", operator)
+}
+
+// typeOrder is the order of the types when values are compared, like jq.
+var typeOrder = map[string]int{
+	"null":    0,
+	"boolean": 1,
+	"number":  2,
+	"string":  3,
+	"array":   4,
+	"object":  5,
+}
+
+// compare returns -1, 0 or 1 whether a is lower, equal or greater than b.
+func compare(a, b interface{}) int {
+	typeA, typeB := typeName(a), typeName(b)
+	if typeA != typeB {
+		return sign(typeOrder[typeA] - typeOrder[typeB])
+	}
+
+	switch typedA := a.(type) {
+	case bool:
+		switch {
+		case typedA == b.(bool):
+			return 0
+		case typedA:
+			return 1
+		}
+		return -1
+	case string:
+		return strings.Compare(typedA, b.(string))
+	case []interface{}:
+		typedB := b.([]interface{})
+		for i := 0; i < len(typedA) && i < len(typedB); i++ {
+			if result := compare(typedA[i], typedB[i]); result != 0 {
+				return result
+			}
+		}
+		return sign(len(typedA) - len(typedB))
+	case map[string]interface{}:
+		typedB := b.(map[string]interface{})
+		keysA, _ := keys(typedA)
+		keysB, _ := keys(typedB)
+		if result := compare(keysA, keysB); result != 0 {
+			return result
+		}
+		for _, key := range sortedKeys(typedA) {
+			if result := compare(typedA[key], typedB[key]); result != 0 {
+				return result
+			}
+		}
+		return 0
+	}
+
+	numberA, _ := toNumber(a)
+	numberB, _ := toNumber(b)
+	switch {
+	case numberA < numberB:
+		return -1
+	case numberA > numberB:
+		return 1
+	}
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
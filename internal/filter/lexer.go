@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenField is a ".name" field access, with the name as text.
+	tokenField
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the decoded value of numbers and strings.
+	value interface{}
+	pos   int
+}
+
+// operators are sorted so the longest operators are matched first.
+var operators = []string{
+	"//", "==", "!=", "<=", ">=", "..",
+	"|", ",", "<", ">", "+", "-", "*", "/", "%",
+	"(", ")", "[", "]", "{", "}", ":", ";", "?", ".",
+}
+
+func lex(expression string) ([]token, error) {
+	tokens := make([]token, 0)
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			// Comments run until the end of the line.
+			for i < len(expression) && expression[i] != '\n' {
+				i++
+			}
+		case c == '.' && i+1 < len(expression) && isIdentStart(expression[i+1]):
+			end := identEnd(expression, i+1)
+			tokens = append(tokens, token{kind: tokenField, text: expression[i+1 : end], pos: i})
+			i = end
+		case isIdentStart(c):
+			end := identEnd(expression, i)
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[i:end], pos: i})
+			i = end
+		case c >= '0' && c <= '9':
+			end := numberEnd(expression, i)
+			number := json.Number(expression[i:end])
+			if _, err := number.Float64(); err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", expression[i:end], i)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:end], value: number, pos: i})
+			i = end
+		case c == '"':
+			end, err := stringEnd(expression, i)
+			if err != nil {
+				return nil, err
+			}
+			var value string
+			if err := json.Unmarshal([]byte(expression[i:end]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string %s at position %d", expression[i:end], i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expression[i:end], value: value, pos: i})
+			i = end
+		case c == '$':
+			return nil, fmt.Errorf("variables are not supported, at position %d", i)
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(expression[i:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, pos: i})
+			i += len(operator)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expression)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func identEnd(expression string, start int) int {
+	end := start
+	for end < len(expression) && (isIdentStart(expression[end]) || (expression[end] >= '0' && expression[end] <= '9')) {
+		end++
+	}
+	return end
+}
+
+func numberEnd(expression string, start int) int {
+	end := start
+	for end < len(expression) {
+		c := expression[end]
+		isExponentSign := (c == '+' || c == '-') && (expression[end-1] == 'e' || expression[end-1] == 'E')
+		if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' && !isExponentSign {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+func stringEnd(expression string, start int) (int, error) {
+	for i := start + 1; i < len(expression); i++ {
+		switch expression[i] {
+		case '\\':
+			if i+1 < len(expression) && expression[i+1] == '(' {
+				return 0, fmt.Errorf("string interpolation is not supported, at position %d", i)
+			}
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at position %d", start)
+}
@@ -0,0 +1,509 @@
+package filter
+
+import (
+	"fmt"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	current := p.tokens[p.pos]
+	if current.kind != tokenEOF {
+		p.pos++
+	}
+	return current
+}
+
+func (p *parser) isOperator(text string) bool {
+	current := p.peek()
+	return current.kind == tokenOperator && current.text == text
+}
+
+func (p *parser) isKeyword(text string) bool {
+	current := p.peek()
+	return current.kind == tokenIdent && current.text == text
+}
+
+func (p *parser) expectOperator(text string) error {
+	if !p.isOperator(text) {
+		return p.unexpected(fmt.Sprintf("expected %q", text))
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expectKeyword(text string) error {
+	if !p.isKeyword(text) {
+		return p.unexpected(fmt.Sprintf("expected %q", text))
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	current := p.peek()
+	if current.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of the filter, %s", expected)
+	}
+
+	text := current.text
+	if current.kind == tokenField {
+		text = "." + text
+	}
+	return fmt.Errorf("unexpected %q at position %d, %s", text, current.pos, expected)
+}
+
+// parsePipe parses the expressions with the lowest precedence, "a | b".
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("|") {
+		return left, nil
+	}
+
+	p.next()
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return pipe{left, right}, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(",") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = comma{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAlternative() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("//") {
+		return left, nil
+	}
+
+	p.next()
+	right, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	return alternative{left, right}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.isOperator(operator) {
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return binary{operator, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("+") || p.isOperator("-") {
+		operator := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{operator, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("*") || p.isOperator("/") || p.isOperator("%") {
+		operator := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{operator, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if !p.isOperator("-") {
+		return p.parsePostfix()
+	}
+
+	p.next()
+	body, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	return negate{body}, nil
+}
+
+// parsePostfix parses a term followed by its suffixes, e.g. ".a[0].b?".
+func (p *parser) parsePostfix() (node, error) {
+	term, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		current := p.peek()
+		switch {
+		case current.kind == tokenField:
+			p.next()
+			term = index{term, literal{current.text}}
+		case p.isOperator("."):
+			p.next()
+			if term, err = p.parseDotSuffix(term); err != nil {
+				return nil, err
+			}
+		case p.isOperator("["):
+			if term, err = p.parseBrackets(term); err != nil {
+				return nil, err
+			}
+		case p.isOperator("?"):
+			p.next()
+			term = optional{term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseDotSuffix parses what follows a ".", which is either a quoted field
+// (."name") or brackets (.[0]).
+func (p *parser) parseDotSuffix(target node) (node, error) {
+	current := p.peek()
+	switch {
+	case current.kind == tokenString:
+		p.next()
+		return index{target, literal{current.value}}, nil
+	case p.isOperator("["):
+		return p.parseBrackets(target)
+	}
+	return nil, p.unexpected(`expected a field name or "["`)
+}
+
+// parseBrackets parses an iteration ([]), an index ([0] or ["name"]) or a
+// slice ([1:3]).
+func (p *parser) parseBrackets(target node) (node, error) {
+	if err := p.expectOperator("["); err != nil {
+		return nil, err
+	}
+	if p.isOperator("]") {
+		p.next()
+		return iterate{target}, nil
+	}
+
+	var from, to node
+	var err error
+	if !p.isOperator(":") {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if p.isOperator("]") {
+			p.next()
+			return index{target, from}, nil
+		}
+	}
+
+	if err := p.expectOperator(":"); err != nil {
+		return nil, err
+	}
+	if !p.isOperator("]") {
+		if to, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectOperator("]"); err != nil {
+		return nil, err
+	}
+	return slice{target, from, to}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	current := p.peek()
+
+	switch current.kind {
+	case tokenField:
+		p.next()
+		return index{identity{}, literal{current.text}}, nil
+	case tokenNumber, tokenString:
+		p.next()
+		return literal{current.value}, nil
+	case tokenIdent:
+		return p.parseIdent()
+	}
+
+	switch {
+	case p.isOperator("."):
+		p.next()
+		if p.peek().kind == tokenString || p.isOperator("[") {
+			return p.parseDotSuffix(identity{})
+		}
+		return identity{}, nil
+	case p.isOperator(".."):
+		p.next()
+		return recurse{}, nil
+	case p.isOperator("("):
+		p.next()
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return body, nil
+	case p.isOperator("["):
+		p.next()
+		if p.isOperator("]") {
+			p.next()
+			return collect{}, nil
+		}
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOperator("]"); err != nil {
+			return nil, err
+		}
+		return collect{body}, nil
+	case p.isOperator("{"):
+		return p.parseObject()
+	}
+
+	return nil, p.unexpected("expected a value")
+}
+
+func (p *parser) parseIdent() (node, error) {
+	name := p.next().text
+
+	switch name {
+	case "true":
+		return literal{true}, nil
+	case "false":
+		return literal{false}, nil
+	case "null":
+		return literal{nil}, nil
+	case "if":
+		return p.parseIf()
+	case "then", "elif", "else", "end", "and", "or":
+		p.pos--
+		return nil, p.unexpected("expected a value")
+	}
+
+	args := make([]node, 0)
+	if p.isOperator("(") {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if !p.isOperator(";") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := functions[functionKey(name, len(args))]; !ok {
+		return nil, fmt.Errorf("unknown function %s/%d", name, len(args))
+	}
+	return call{name, args}, nil
+}
+
+// parseIf parses a conditional, after its "if" or "elif".
+func (p *parser) parseIf() (node, error) {
+	condition, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isKeyword("elif"):
+		p.next()
+		otherwise, err := p.parseIf()
+		if err != nil {
+			return nil, err
+		}
+		return conditional{condition, then, otherwise}, nil
+	case p.isKeyword("else"):
+		p.next()
+		otherwise, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("end"); err != nil {
+			return nil, err
+		}
+		return conditional{condition, then, otherwise}, nil
+	}
+
+	if err := p.expectKeyword("end"); err != nil {
+		return nil, err
+	}
+	return conditional{condition, then, identity{}}, nil
+}
+
+// parseObject parses an object construction, e.g. {id, name: .user.name}.
+func (p *parser) parseObject() (node, error) {
+	if err := p.expectOperator("{"); err != nil {
+		return nil, err
+	}
+
+	object := construct{}
+	for !p.isOperator("}") {
+		var key, value node
+		current := p.peek()
+
+		switch {
+		case current.kind == tokenIdent || current.kind == tokenString:
+			p.next()
+			name := current.text
+			if current.kind == tokenString {
+				name = current.value.(string)
+			}
+			key = literal{name}
+			// Without a value, {name} is a shorthand of {name: .name}.
+			value = index{identity{}, key}
+		case p.isOperator("("):
+			p.next()
+			var err error
+			if key, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.unexpected("expected the key of an object")
+		}
+
+		if p.isOperator(":") {
+			p.next()
+			var err error
+			if value, err = p.parseObjectValue(); err != nil {
+				return nil, err
+			}
+		} else if value == nil {
+			return nil, p.unexpected(`expected ":"`)
+		}
+		object.entries = append(object.entries, entry{key, value})
+
+		if !p.isOperator(",") {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expectOperator("}"); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// parseObjectValue parses the value of an object entry, which can't have
+// commas since they separate the entries.
+func (p *parser) parseObjectValue() (node, error) {
+	value, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("|") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		value = pipe{value, right}
+	}
+	return value, nil
+}
@@ -0,0 +1,150 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joaocgduarte/httpmate/internal/extract"
+	"gopkg.in/yaml.v3"
+)
+
+// Flow is a sequence of requests of the collections, which share the
+// variables extracted from their responses.
+type Flow struct {
+	Name string `json:"name,omitempty"`
+	// Environment is used unless another one is chosen when running the flow.
+	Environment string            `json:"env,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Steps       []Step            `json:"steps"`
+}
+
+type Step struct {
+	Name string `json:"name,omitempty"`
+	// Request is the "collection/request" to perform.
+	Request string `json:"request"`
+	// Variables override the ones of the flow and of the environment.
+	Variables map[string]string `json:"variables,omitempty"`
+	When      *Condition        `json:"when,omitempty"`
+	// ExpectStatus are the status codes with which the step succeeds. By
+	// default, any status below 400 succeeds.
+	ExpectStatus []int                `json:"expect_status,omitempty"`
+	Extract      []extract.Extraction `json:"extract,omitempty"`
+}
+
+// Condition makes a step run only if a previous step got one of the given
+// status codes.
+type Condition struct {
+	// Step is the name of the previous step, or empty for the step right
+	// before.
+	Step   string `json:"step,omitempty"`
+	Status []int  `json:"status"`
+}
+
+// ParseFile reads a flow file, written in YAML or JSON.
+func ParseFile(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	flow, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flow file %s: %w", path, err)
+	}
+	return flow, nil
+}
+
+// Parse decodes a flow written in YAML or JSON. YAML is converted to JSON, so
+// that the fields are named as in the request configurations.
+func Parse(data []byte) (*Flow, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var flow Flow
+	if err := json.Unmarshal(encoded, &flow); err != nil {
+		return nil, err
+	}
+
+	if err := flow.validate(); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+func (f *Flow) validate() error {
+	if len(f.Steps) == 0 {
+		return fmt.Errorf("the flow has no steps")
+	}
+
+	names := map[string]bool{}
+	for i, step := range f.Steps {
+		if step.Request == "" {
+			return fmt.Errorf("step %d has no request", i+1)
+		}
+
+		if step.When != nil {
+			if step.When.Step == "" && i == 0 {
+				return fmt.Errorf("step %d has a condition but no previous step", i+1)
+			}
+			if step.When.Step != "" && !names[step.When.Step] {
+				return fmt.Errorf("step %d has a condition on step %q, which is not a previous step", i+1, step.When.Step)
+			}
+		}
+
+		if step.Name != "" {
+			if names[step.Name] {
+				return fmt.Errorf("duplicated step name %q", step.Name)
+			}
+			names[step.Name] = true
+		}
+	}
+	return nil
+}
+
+// Title identifies the step in the output.
+func (s Step) Title() string {
+	if s.Name != "" {
+		return fmt.Sprintf("%s (%s)", s.Name, s.Request)
+	}
+	return s.Request
+}
+
+// Succeeded reports whether the step succeeds with the given status code.
+func (s Step) Succeeded(statusCode int) bool {
+	if len(s.ExpectStatus) == 0 {
+		return statusCode < 400
+	}
+	return containsStatus(s.ExpectStatus, statusCode)
+}
+
+// Matches reports whether the condition is met, given the status codes of
+// the steps performed so far (by name) and of the step right before. Steps
+// which were skipped have no status code.
+func (c *Condition) Matches(statusCodes map[string]int, previousStatusCode int) bool {
+	if c == nil {
+		return true
+	}
+
+	statusCode := previousStatusCode
+	if c.Step != "" {
+		statusCode = statusCodes[c.Step]
+	}
+	return statusCode != 0 && containsStatus(c.Status, statusCode)
+}
+
+func containsStatus(statusCodes []int, statusCode int) bool {
+	for _, expected := range statusCodes {
+		if expected == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package har
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+)
+
+// Headers which are set by the HTTP client itself, or which would break the
+// request if they were copied (e.g. Accept-Encoding disables the automatic
+// decompression of the responses).
+var skippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// Filter selects the entries to import. Empty fields match every entry.
+type Filter struct {
+	// Domains match the host of the entry, or any of its parent domains.
+	Domains []string
+	Methods []string
+}
+
+func (f Filter) matches(method string, u *url.URL) bool {
+	if len(f.Methods) > 0 {
+		found := false
+		for _, filterMethod := range f.Methods {
+			found = found || strings.EqualFold(filterMethod, method)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Domains) == 0 {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range f.Domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+type ImportResult struct {
+	Requests []*configs.RequestConfig
+	Warnings []string
+}
+
+// Import creates a request config for each entry of an HTTP Archive that
+// matches the filter.
+func Import(data []byte, filter Filter) (*ImportResult, error) {
+	archive, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Requests: make([]*configs.RequestConfig, 0),
+		Warnings: make([]string, 0),
+	}
+	usedNames := map[string]bool{}
+
+	for i, entry := range archive.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("entry %d: invalid URL %q", i+1, entry.Request.URL))
+			continue
+		}
+
+		if !filter.matches(entry.Request.Method, u) {
+			continue
+		}
+
+		reqConfig := importRequest(entry.Request, u)
+
+		name := files.SafeFileName(fmt.Sprintf("%s %s", reqConfig.Method, u.Path))
+		reqConfig.RequestName = name
+		for j := 2; usedNames[reqConfig.RequestName]; j++ {
+			reqConfig.RequestName = fmt.Sprintf("%s-%d", name, j)
+		}
+		usedNames[reqConfig.RequestName] = true
+
+		for _, param := range postParamFiles(entry.Request.PostData) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: set the path of the file of the %s part", reqConfig.RequestName, param))
+		}
+
+		result.Requests = append(result.Requests, reqConfig)
+	}
+
+	return result, nil
+}
+
+func importRequest(request Request, u *url.URL) *configs.RequestConfig {
+	reqConfig := &configs.RequestConfig{
+		Domain:      fmt.Sprintf("%s://%s", u.Scheme, u.Host),
+		Path:        u.Path,
+		Method:      strings.ToUpper(request.Method),
+		QueryParams: map[string]string{},
+		Headers:     map[string]string{},
+	}
+
+	for key, values := range u.Query() {
+		reqConfig.QueryParams[key] = values[0]
+	}
+
+	for _, header := range request.Headers {
+		name := strings.ToLower(header.Name)
+		// HTTP/2 pseudo-headers, such as ":authority".
+		if strings.HasPrefix(name, ":") || skippedHeaders[name] {
+			continue
+		}
+		if name == "content-type" {
+			reqConfig.ContentType = header.Value
+			continue
+		}
+		reqConfig.Headers[header.Name] = header.Value
+	}
+
+	if request.PostData != nil {
+		importPostData(reqConfig, request.PostData)
+	}
+
+	return reqConfig
+}
+
+func importPostData(reqConfig *configs.RequestConfig, postData *PostData) {
+	if postData.MimeType != "" {
+		reqConfig.ContentType = postData.MimeType
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(reqConfig.ContentType)
+	switch {
+	case configs.ContentType(mediaType) == configs.ContentTypeFormURLEncoded:
+		reqConfig.Body.FormURLEncoded = map[string]string{}
+		if len(postData.Params) > 0 {
+			for _, param := range postData.Params {
+				reqConfig.Body.FormURLEncoded[unescape(param.Name)] = unescape(param.Value)
+			}
+			return
+		}
+		values, err := url.ParseQuery(postData.Text)
+		if err == nil {
+			for key := range values {
+				reqConfig.Body.FormURLEncoded[key] = values.Get(key)
+			}
+			return
+		}
+		reqConfig.Body.FormURLEncoded = nil
+	case configs.ContentType(mediaType) == configs.ContentTypeMultipartFormData && len(postData.Params) > 0:
+		reqConfig.ContentType = string(configs.ContentTypeMultipartFormData)
+		reqConfig.Body.MultipartBody = make([]*configs.MultipartBodyConfig, 0, len(postData.Params))
+		for _, param := range postData.Params {
+			part := &configs.MultipartBodyConfig{Key: param.Name}
+			if param.FileName != "" {
+				fileName := param.FileName
+				part.BinaryFilePathValue = &fileName
+			} else {
+				value := param.Value
+				part.PlainTextValue = &value
+			}
+			reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, part)
+		}
+		return
+	}
+
+	text := postData.Text
+	reqConfig.Body.RawBody = &text
+}
+
+// postParamFiles returns the names of the multipart params which are files,
+// since the HAR only has their names and not their paths.
+func postParamFiles(postData *PostData) []string {
+	result := make([]string, 0)
+	if postData == nil {
+		return result
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(postData.MimeType)
+	if configs.ContentType(mediaType) != configs.ContentTypeMultipartFormData {
+		return result
+	}
+
+	for _, param := range postData.Params {
+		if param.FileName != "" {
+			result = append(result, param.Name)
+		}
+	}
+	return result
+}
+
+// unescape decodes the form params, which some browsers record encoded.
+func unescape(value string) string {
+	unescaped, err := url.QueryUnescape(value)
+	if err != nil {
+		return value
+	}
+	return unescaped
+}
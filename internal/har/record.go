@@ -0,0 +1,274 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joaocgduarte/httpmate/internal/download"
+	"github.com/joaocgduarte/httpmate/internal/timing"
+)
+
+// BodyRecorder keeps a copy of a body while it's being read, so it can be
+// recorded after the request is sent or the response is printed. Response
+// bodies which are saved or binary are only counted, so downloads aren't
+// kept in memory.
+type BodyRecorder struct {
+	io.ReadCloser
+	buffer bytes.Buffer
+	size   int64
+	// omitBinary omits the content of the body if it's binary, which is
+	// sniffed from its first bytes if contentType doesn't tell.
+	omitBinary  bool
+	contentType string
+	sniffed     bool
+	// omitted explains why the content isn't kept, if it isn't.
+	omitted string
+}
+
+func (b *BodyRecorder) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if b.omitted != "" {
+		return n, err
+	}
+
+	b.buffer.Write(p[:n])
+	if b.omitBinary && !b.sniffed && b.buffer.Len() >= download.SniffLength {
+		b.sniff()
+	}
+	return n, err
+}
+
+// sniff omits the content of the body if it's binary.
+func (b *BodyRecorder) sniff() {
+	b.sniffed = true
+	sniffed := b.buffer.Bytes()
+	if len(sniffed) > download.SniffLength {
+		sniffed = sniffed[:download.SniffLength]
+	}
+	if download.IsBinary(b.contentType, sniffed) {
+		b.omitted = "the content of the binary body was not recorded"
+		b.buffer = bytes.Buffer{}
+	}
+}
+
+func (b *BodyRecorder) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+	return b.buffer.Bytes()
+}
+
+// Size returns how many bytes of the body were read.
+func (b *BodyRecorder) Size() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.size
+}
+
+// content returns the recorded content of a response body.
+func (b *BodyRecorder) content(mimeType string) Content {
+	if b == nil {
+		return Content{MimeType: mimeType}
+	}
+	if b.omitted == "" && b.omitBinary && !b.sniffed {
+		b.sniff()
+	}
+	if b.omitted != "" {
+		return Content{Size: b.size, MimeType: mimeType, Comment: b.omitted}
+	}
+	return content(mimeType, b.buffer.Bytes())
+}
+
+// RecordBody replaces the body with a recorder of its content. It returns nil
+// if there's no body.
+func RecordBody(body *io.ReadCloser) *BodyRecorder {
+	if *body == nil || *body == http.NoBody {
+		return nil
+	}
+
+	recorder := &BodyRecorder{ReadCloser: *body}
+	*body = recorder
+	return recorder
+}
+
+// RecordResponseBody is like RecordBody, but only the size of the body is
+// recorded if it's saved to a file, or if it's binary.
+func RecordResponseBody(resp *http.Response, saved bool) *BodyRecorder {
+	recorder := RecordBody(&resp.Body)
+	if recorder == nil {
+		return nil
+	}
+
+	recorder.omitBinary = true
+	recorder.contentType = resp.Header.Get("Content-Type")
+	if saved {
+		recorder.omitted = "the content of the body was saved to a file, so it was not recorded"
+	}
+	return recorder
+}
+
+// NewEntry creates the entry of an executed request. The request and
+// response bodies must be the ones that were sent and received.
+func NewEntry(
+	req *http.Request,
+	requestBody []byte,
+	resp *http.Response,
+	responseBody *BodyRecorder,
+	startTime time.Time,
+	timings Timings,
+) Entry {
+	entry := Entry{
+		StartedDateTime: startTime.Format(time.RFC3339Nano),
+		Request: Request{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     requestCookies(req),
+			Headers:     nameValues(req.Header),
+			QueryString: make([]NameValue, 0),
+			HeadersSize: -1,
+			BodySize:    int64(len(requestBody)),
+		},
+		Response: Response{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Cookies:     responseCookies(resp),
+			Headers:     nameValues(resp.Header),
+			Content:     responseBody.content(resp.Header.Get("Content-Type")),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    responseBody.Size(),
+		},
+		Timings: timings,
+	}
+
+	if entry.Request.HTTPVersion == "" {
+		entry.Request.HTTPVersion = resp.Proto
+	}
+
+	if host := req.Host; host != "" && req.Header.Get("Host") == "" {
+		entry.Request.Headers = append(entry.Request.Headers, NameValue{Name: "Host", Value: host})
+	}
+
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, NameValue{Name: key, Value: value})
+		}
+	}
+	sort.Slice(entry.Request.QueryString, func(i, j int) bool {
+		return entry.Request.QueryString[i].Name < entry.Request.QueryString[j].Name
+	})
+
+	if requestBody != nil {
+		entry.Request.PostData = &PostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(requestBody),
+		}
+	}
+
+	entry.Time = timings.total()
+	return entry
+}
+
+// NewTimings converts the phases of a request to HAR timings, in which
+// connect includes the TLS handshake. The phases which didn't happen are -1.
+func NewTimings(timings timing.Timings) Timings {
+	optional := func(duration time.Duration) float64 {
+		if duration <= 0 {
+			return -1
+		}
+		return milliseconds(duration)
+	}
+
+	return Timings{
+		Blocked: -1,
+		DNS:     optional(timings.DNS),
+		Connect: optional(timings.Connect + timings.TLS),
+		Send:    milliseconds(timings.Send),
+		Wait:    milliseconds(timings.Wait),
+		Receive: milliseconds(timings.Transfer),
+		SSL:     optional(timings.TLS),
+	}
+}
+
+func (t Timings) total() float64 {
+	total := 0.0
+	// SSL is already included in connect.
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+func content(mimeType string, body []byte) Content {
+	result := Content{
+		Size:     int64(len(body)),
+		MimeType: mimeType,
+	}
+
+	if utf8.Valid(body) {
+		result.Text = string(body)
+		return result
+	}
+
+	result.Text = base64.StdEncoding.EncodeToString(body)
+	result.Encoding = "base64"
+	return result
+}
+
+func nameValues(header http.Header) []NameValue {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]NameValue, 0, len(header))
+	for _, key := range keys {
+		for _, value := range header[key] {
+			result = append(result, NameValue{Name: key, Value: value})
+		}
+	}
+	return result
+}
+
+func requestCookies(req *http.Request) []Cookie {
+	result := make([]Cookie, 0)
+	for _, cookie := range req.Cookies() {
+		result = append(result, Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return result
+}
+
+func responseCookies(resp *http.Response) []Cookie {
+	result := make([]Cookie, 0)
+	for _, cookie := range resp.Cookies() {
+		harCookie := Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+		if !cookie.Expires.IsZero() {
+			harCookie.Expires = cookie.Expires.Format(time.RFC3339)
+		}
+		result = append(result, harCookie)
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package har
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newResponse(contentType string, body []byte) *http.Response {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+	return resp
+}
+
+func TestRecordResponseBody(t *testing.T) {
+	binary := bytes.Repeat([]byte{0, 1, 2, 0xff}, 1024)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		saved       bool
+		expected    Content
+	}{
+		{
+			name:        "text",
+			contentType: "application/json",
+			body:        []byte(`{"id":1}`),
+			expected:    Content{Size: 8, MimeType: "application/json", Text: `{"id":1}`},
+		},
+		{
+			name:        "saved text",
+			contentType: "text/csv",
+			body:        []byte("a,b\n1,2\n"),
+			saved:       true,
+			expected:    Content{Size: 8, MimeType: "text/csv", Comment: "the content of the body was saved to a file, so it was not recorded"},
+		},
+		{
+			name:        "saved binary",
+			contentType: "application/pdf",
+			body:        binary,
+			saved:       true,
+			expected:    Content{Size: 4096, MimeType: "application/pdf", Comment: "the content of the body was saved to a file, so it was not recorded"},
+		},
+		{
+			name:        "binary content type",
+			contentType: "application/pdf",
+			body:        binary,
+			expected:    Content{Size: 4096, MimeType: "application/pdf", Comment: "the content of the binary body was not recorded"},
+		},
+		{
+			name:     "sniffed binary",
+			body:     binary,
+			expected: Content{Size: 4096, Comment: "the content of the binary body was not recorded"},
+		},
+		{
+			name:        "short binary",
+			contentType: "image/png",
+			body:        []byte{0x89, 'P', 'N', 'G'},
+			expected:    Content{Size: 4, MimeType: "image/png", Comment: "the content of the binary body was not recorded"},
+		},
+		{
+			name:     "sniffed text",
+			body:     []byte(strings.Repeat("text ", 200)),
+			expected: Content{Size: 1000, Text: strings.Repeat("text ", 200)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := newResponse(test.contentType, test.body)
+			recorder := RecordResponseBody(resp, test.saved)
+
+			// The body is still read in full by the response printer or
+			// the download.
+			read, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(read, test.body) {
+				t.Error("expected the body to be read as it is")
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "https://example.com/file", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry := NewEntry(req, nil, resp, recorder, time.Now(), Timings{})
+
+			if entry.Response.Content != test.expected {
+				t.Errorf("expected the content %+v, got %+v", test.expected, entry.Response.Content)
+			}
+			if entry.Response.BodySize != int64(len(test.body)) {
+				t.Errorf("expected the body size %d, got %d", len(test.body), entry.Response.BodySize)
+			}
+			if test.expected.Comment != "" && len(recorder.Bytes()) != 0 {
+				t.Errorf("expected the content not to be kept, got %d bytes", len(recorder.Bytes()))
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package httpfile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	Extension = ".http"
+	separator = "###"
+)
+
+var (
+	variableRegex    = regexp.MustCompile(`^@([A-Za-z0-9_.\-]+)\s*=\s*(.*)$`)
+	nameCommentRegex = regexp.MustCompile(`^(?:#|//)\s*@name\s*=?\s*(\S+)`)
+	requestLineRegex = regexp.MustCompile(`^([A-Z]+)\s+(.+?)(?:\s+HTTP/[0-9.]+)?$`)
+	versionRegex     = regexp.MustCompile(`\s+HTTP/[0-9.]+$`)
+	nameReplacer     = strings.NewReplacer("/", "-", "\\", "-")
+	methods          = map[string]bool{
+		"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+		"DELETE": true, "CONNECT": true, "OPTIONS": true, "TRACE": true,
+	}
+)
+
+// File is a REST Client / JetBrains HTTP Client ".http" file, with multiple
+// requests separated by "###" and file variables defined as "@name = value".
+type File struct {
+	Variables map[string]string
+	Requests  []Request
+}
+
+type Header struct {
+	Name  string
+	Value string
+}
+
+type Request struct {
+	// Name comes from a "# @name" comment, or from the title of the "###"
+	// separator. Unnamed requests are named after their position.
+	Name    string
+	Method  string
+	URL     string
+	Headers []Header
+	Body    string
+	// BodyFile is set when the body is read from a file ("< ./file").
+	BodyFile string
+}
+
+// ParseFile reads and parses a .http file.
+func ParseFile(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file %s: %w", Extension, path, err)
+	}
+	return file, nil
+}
+
+// RequestNames returns the names of the requests of a .http file.
+func RequestNames(path string) ([]string, error) {
+	file, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(file.Requests))
+	for _, request := range file.Requests {
+		names = append(names, request.Name)
+	}
+	return names, nil
+}
+
+// Find returns the request with the given name.
+func (f *File) Find(name string) (*Request, bool) {
+	for i := range f.Requests {
+		if f.Requests[i].Name == name {
+			return &f.Requests[i], true
+		}
+	}
+	return nil, false
+}
+
+type section struct {
+	title string
+	lines []string
+}
+
+// Parse parses the content of a .http file.
+func Parse(content string) (*File, error) {
+	file := &File{
+		Variables: map[string]string{},
+		Requests:  make([]Request, 0),
+	}
+
+	sections := []section{{}}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, separator) {
+			sections = append(sections, section{title: strings.TrimSpace(strings.TrimLeft(line, "#"))})
+			continue
+		}
+		sections[len(sections)-1].lines = append(sections[len(sections)-1].lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	usedNames := map[string]bool{}
+	for _, s := range sections {
+		request, found, err := parseSection(s, file.Variables)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		// Names are used as paths, e.g. "file/request".
+		request.Name = nameReplacer.Replace(request.Name)
+		if request.Name == "" {
+			request.Name = fmt.Sprintf("request-%d", len(file.Requests)+1)
+		}
+		if usedNames[request.Name] {
+			return nil, fmt.Errorf("duplicated request name %q", request.Name)
+		}
+		usedNames[request.Name] = true
+		file.Requests = append(file.Requests, request)
+	}
+
+	return file, nil
+}
+
+// parseSection parses the request between two separators. Sections without
+// a request line, e.g. with only variables, are not requests.
+func parseSection(s section, variables map[string]string) (Request, bool, error) {
+	request := Request{Name: s.title, Headers: make([]Header, 0)}
+
+	i := 0
+	for ; i < len(s.lines); i++ {
+		line := strings.TrimSpace(s.lines[i])
+		if match := nameCommentRegex.FindStringSubmatch(line); match != nil {
+			request.Name = match[1]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if match := variableRegex.FindStringSubmatch(line); match != nil {
+			variables[match[1]] = strings.TrimSpace(match[2])
+			continue
+		}
+		break
+	}
+
+	if i >= len(s.lines) {
+		return request, false, nil
+	}
+
+	requestLine := strings.TrimSpace(s.lines[i])
+	if match := requestLineRegex.FindStringSubmatch(requestLine); match != nil && methods[match[1]] {
+		request.Method = match[1]
+		request.URL = match[2]
+	} else if !strings.Contains(requestLine, " ") {
+		request.Method = "GET"
+		request.URL = requestLine
+	} else {
+		return request, false, fmt.Errorf("invalid request line %q", requestLine)
+	}
+	i++
+
+	// Long query strings can continue in the next lines.
+	for ; i < len(s.lines); i++ {
+		line := strings.TrimSpace(s.lines[i])
+		if !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "&") {
+			break
+		}
+		request.URL += versionRegex.ReplaceAllString(line, "")
+	}
+
+	for ; i < len(s.lines); i++ {
+		line := strings.TrimSpace(s.lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return request, false, fmt.Errorf("invalid header %q", line)
+		}
+		request.Headers = append(request.Headers, Header{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	bodyLines := make([]string, 0)
+	for ; i < len(s.lines); i++ {
+		// Response handlers and references are specific to the IDEs.
+		trimmed := strings.TrimSpace(s.lines[i])
+		if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">> ") || strings.HasPrefix(trimmed, "<> ") {
+			break
+		}
+		bodyLines = append(bodyLines, s.lines[i])
+	}
+	for len(bodyLines) > 0 && strings.TrimSpace(bodyLines[len(bodyLines)-1]) == "" {
+		bodyLines = bodyLines[:len(bodyLines)-1]
+	}
+
+	if len(bodyLines) == 1 && strings.HasPrefix(strings.TrimSpace(bodyLines[0]), "< ") {
+		request.BodyFile = strings.TrimSpace(strings.TrimSpace(bodyLines[0])[2:])
+		return request, true, nil
+	}
+	request.Body = strings.Join(bodyLines, "\n")
+
+	return request, true, nil
+}
+
+// Format renders a .http file.
+func Format(file *File) string {
+	var b strings.Builder
+
+	if len(file.Variables) > 0 {
+		names := make([]string, 0, len(file.Variables))
+		for name := range file.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "@%s = %s\n", name, file.Variables[name])
+		}
+		b.WriteString("\n")
+	}
+
+	for i, request := range file.Requests {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s %s\n", separator, request.Name)
+		fmt.Fprintf(&b, "# @name %s\n", request.Name)
+		fmt.Fprintf(&b, "%s %s\n", request.Method, request.URL)
+		for _, header := range request.Headers {
+			fmt.Fprintf(&b, "%s: %s\n", header.Name, header.Value)
+		}
+
+		switch {
+		case request.BodyFile != "":
+			fmt.Fprintf(&b, "\n< %s\n", request.BodyFile)
+		case request.Body != "":
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimRight(request.Body, "\n"))
+		}
+	}
+
+	return b.String()
+}
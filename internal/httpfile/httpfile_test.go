@@ -0,0 +1,188 @@
+package httpfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const content = `@host = https://api.example.com
+@token = {{secret}}
+
+### List users
+GET {{host}}/users
+    ?page=2
+    &sort=name HTTP/1.1
+Accept: application/json
+// a comment between the headers
+Authorization: Bearer {{token}}
+
+### Create a user
+# @name create
+POST {{host}}/users HTTP/1.1
+Content-Type: application/json
+
+{
+  "name": "httpmate"
+}
+
+> {% client.global.set("id", response.body.id); %}
+
+###
+# The body is read from a file
+PUT {{host}}/avatar
+Content-Type: image/png
+
+< ./avatar.png
+
+###
+@trailing = value
+
+###
+{{host}}/health
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedVariables := map[string]string{
+		"host":     "https://api.example.com",
+		"token":    "{{secret}}",
+		"trailing": "value",
+	}
+	if !reflect.DeepEqual(file.Variables, expectedVariables) {
+		t.Errorf("expected the variables %v, got %v", expectedVariables, file.Variables)
+	}
+
+	expected := []Request{
+		{
+			Name:   "List users",
+			Method: "GET",
+			URL:    "{{host}}/users?page=2&sort=name",
+			Headers: []Header{
+				{Name: "Accept", Value: "application/json"},
+				{Name: "Authorization", Value: "Bearer {{token}}"},
+			},
+		},
+		{
+			Name:    "create",
+			Method:  "POST",
+			URL:     "{{host}}/users",
+			Headers: []Header{{Name: "Content-Type", Value: "application/json"}},
+			Body:    "{\n  \"name\": \"httpmate\"\n}",
+		},
+		{
+			Name:     "request-3",
+			Method:   "PUT",
+			URL:      "{{host}}/avatar",
+			Headers:  []Header{{Name: "Content-Type", Value: "image/png"}},
+			BodyFile: "./avatar.png",
+		},
+		{
+			Name:    "request-4",
+			Method:  "GET",
+			URL:     "{{host}}/health",
+			Headers: []Header{},
+		},
+	}
+	if !reflect.DeepEqual(file.Requests, expected) {
+		t.Errorf("expected the requests\n%+v\ngot\n%+v", expected, file.Requests)
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	file, err := Parse("### users/list\nGET /users\n\n###\n// @name=get\nGET /users/1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{file.Requests[0].Name, file.Requests[1].Name}
+	if !reflect.DeepEqual(names, []string{"users-list", "get"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	if _, found := file.Find("get"); !found {
+		t.Error("expected to find the request by its name")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{content: "GET /a\n###\nGET /b\n###\n# @name request-1\nGET /c", expected: `duplicated request name "request-1"`},
+		{content: "GET /a\nnot a header", expected: `invalid header "not a header"`},
+		{content: "FETCH the /a", expected: `invalid request line "FETCH the /a"`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.content)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected the error %q, got %v", test.expected, err)
+		}
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	file := &File{
+		Variables: map[string]string{"host": "https://api.example.com", "user": "joao"},
+		Requests: []Request{
+			{
+				Name:    "get",
+				Method:  "GET",
+				URL:     "{{host}}/users?name={{user}}",
+				Headers: []Header{{Name: "Accept", Value: "application/json"}},
+			},
+			{
+				Name:    "form",
+				Method:  "POST",
+				URL:     "{{host}}/login",
+				Headers: []Header{{Name: "Content-Type", Value: "application/x-www-form-urlencoded"}},
+				Body:    "password=secret&username={{user}}",
+			},
+			{
+				Name:   "multipart",
+				Method: "POST",
+				URL:    "{{host}}/upload",
+				Headers: []Header{
+					{Name: "Content-Type", Value: "multipart/form-data; boundary=boundary"},
+					{Name: "Authorization", Value: "Basic joao:secret"},
+				},
+				Body: "--boundary\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\n\n< ./a.txt\n--boundary--",
+			},
+			{
+				Name:     "file",
+				Method:   "PUT",
+				URL:      "{{host}}/avatar",
+				Headers:  []Header{},
+				BodyFile: "/tmp/avatar.png",
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "users"+Extension)
+	if err := os.WriteFile(path, []byte(Format(file)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed, file) {
+		t.Errorf("expected\n%+v\ngot\n%+v", file, parsed)
+	}
+
+	names, err := RequestNames(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "get,form,multipart,file" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
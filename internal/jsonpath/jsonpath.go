@@ -0,0 +1,109 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when the path does not exist in the document.
+var ErrNotFound = errors.New("path not found")
+
+// Lookup returns the value at path inside a JSON document. Paths use the dot
+// notation with optional brackets, e.g. "$.data.items[0].id", "data.items[0]"
+// or "$['content-type']". Negative indexes count from the end of an array.
+func Lookup(document []byte, path string) (interface{}, error) {
+	var parsed interface{}
+	if err := json.Unmarshal(document, &parsed); err != nil {
+		return nil, fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	return LookupValue(parsed, path)
+}
+
+// LookupValue is the same as Lookup, over an already decoded JSON value.
+func LookupValue(value interface{}, path string) (interface{}, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := value
+	for _, segment := range segments {
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			if segment.isIndex {
+				return nil, ErrNotFound
+			}
+			next, ok := typed[segment.key]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			current = next
+		case []interface{}:
+			if !segment.isIndex {
+				return nil, ErrNotFound
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(typed)
+			}
+			if index < 0 || index >= len(typed) {
+				return nil, ErrNotFound
+			}
+			current = typed[index]
+		default:
+			return nil, ErrNotFound
+		}
+	}
+
+	return current, nil
+}
+
+type segment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parse(path string) ([]segment, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	segments := make([]segment, 0)
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ']'", path)
+			}
+			content := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+
+			if len(content) >= 2 && (content[0] == '"' || content[0] == '\'') && content[len(content)-1] == content[0] {
+				segments = append(segments, segment{key: content[1 : len(content)-1]})
+				continue
+			}
+
+			index, err := strconv.Atoi(content)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: invalid index %q", path, content)
+			}
+			segments = append(segments, segment{index: index, isIndex: true})
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, segment{key: path[i : i+end]})
+			i += end
+		}
+	}
+
+	return segments, nil
+}
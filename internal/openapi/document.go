@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxReferenceDepth = 32
+
+// document is an OpenAPI 3 or Swagger 2 spec, decoded as generic values so
+// both versions can be read with the same helpers.
+type document struct {
+	root      map[string]interface{}
+	isSwagger bool
+}
+
+// resolve follows local $ref references ("#/components/schemas/User"),
+// returning the referenced value. External references are not supported.
+func (d *document) resolve(value interface{}) (map[string]interface{}, error) {
+	current := mapValue(value)
+	for depth := 0; current != nil; depth++ {
+		ref, ok := current["$ref"].(string)
+		if !ok {
+			return current, nil
+		}
+		if depth >= maxReferenceDepth {
+			return nil, fmt.Errorf("too many nested references at %s", ref)
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("external reference %s is not supported", ref)
+		}
+
+		var target interface{} = d.root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			target = mapValue(target)[part]
+		}
+		if target == nil {
+			return nil, fmt.Errorf("reference %s does not exist", ref)
+		}
+		current = mapValue(target)
+	}
+	return current, nil
+}
+
+// normalize converts the maps decoded from YAML, which can have non-string
+// keys (e.g. response codes), into maps with string keys.
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			typed[key] = normalize(item)
+		}
+		return typed
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[fmt.Sprint(key)] = normalize(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = normalize(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+func mapValue(value interface{}) map[string]interface{} {
+	result, _ := value.(map[string]interface{})
+	return result
+}
+
+func listValue(value interface{}) []interface{} {
+	result, _ := value.([]interface{})
+	return result
+}
+
+func stringValue(value interface{}) string {
+	result, _ := value.(string)
+	return result
+}
+
+// specVersion formats the openapi or swagger version of a spec. Unquoted
+// versions are numbers in YAML, e.g. 2.0 for "2.0" (and 3.1 for "3.1").
+func specVersion(value interface{}) string {
+	switch typed := value.(type) {
+	case float64:
+		version := strconv.FormatFloat(typed, 'f', -1, 64)
+		if !strings.Contains(version, ".") {
+			version += ".0"
+		}
+		return version
+	case int:
+		return strconv.Itoa(typed) + ".0"
+	}
+	return formatValue(value)
+}
+
+// formatValue formats an example value to be used in a query parameter,
+// header or form field.
+func formatValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case map[string]interface{}, []interface{}:
+		result, err := json.Marshal(typed)
+		if err != nil {
+			return ""
+		}
+		return string(result)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
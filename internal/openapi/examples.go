@@ -0,0 +1,172 @@
+package openapi
+
+const maxSampleDepth = 8
+
+// parameterExample returns the example of a parameter, looking at its
+// examples, its schema, and the Swagger 2 "x-example" extension.
+func (d *document) parameterExample(parameter map[string]interface{}) (interface{}, bool) {
+	if example, ok := parameter["example"]; ok {
+		return example, true
+	}
+	if example, ok := parameter["x-example"]; ok {
+		return example, true
+	}
+	if example, ok := d.firstExample(parameter["examples"]); ok {
+		return example, true
+	}
+
+	// Swagger 2 parameters have the schema keywords at their top level.
+	schema := mapValue(parameter["schema"])
+	if schema == nil {
+		schema = parameter
+	}
+	return d.schemaExample(schema)
+}
+
+// mediaTypeExample returns the example of a media type of a request body, or
+// a sample generated from its schema.
+func (d *document) mediaTypeExample(mediaType map[string]interface{}) (interface{}, bool) {
+	if example, ok := mediaType["example"]; ok {
+		return example, true
+	}
+	if example, ok := d.firstExample(mediaType["examples"]); ok {
+		return example, true
+	}
+
+	schema, err := d.resolve(mediaType["schema"])
+	if err != nil || schema == nil {
+		return nil, false
+	}
+	return d.sample(schema, 0), true
+}
+
+// firstExample returns the value of the first (by name) of the examples of
+// an OpenAPI 3 parameter or media type.
+func (d *document) firstExample(value interface{}) (interface{}, bool) {
+	examples := mapValue(value)
+	for _, name := range sortedKeys(examples) {
+		example, err := d.resolve(examples[name])
+		if err != nil || example == nil {
+			continue
+		}
+		if value, ok := example["value"]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// schemaExample returns the explicit example of a schema, without generating
+// a sample from its type.
+func (d *document) schemaExample(schemaValue interface{}) (interface{}, bool) {
+	schema, err := d.resolve(schemaValue)
+	if err != nil || schema == nil {
+		return nil, false
+	}
+
+	for _, keyword := range []string{"example", "default"} {
+		if example, ok := schema[keyword]; ok {
+			return example, true
+		}
+	}
+	if enum := listValue(schema["enum"]); len(enum) > 0 {
+		return enum[0], true
+	}
+	return nil, false
+}
+
+// sample generates an example value from a schema, using the examples of its
+// properties when there are any.
+func (d *document) sample(schema map[string]interface{}, depth int) interface{} {
+	if example, ok := d.schemaExample(schema); ok {
+		return example
+	}
+	if depth > maxSampleDepth {
+		return nil
+	}
+
+	if allOf := listValue(schema["allOf"]); len(allOf) > 0 {
+		result := map[string]interface{}{}
+		for _, item := range allOf {
+			itemSchema, err := d.resolve(item)
+			if err != nil {
+				continue
+			}
+			for key, value := range mapValue(d.sample(itemSchema, depth+1)) {
+				result[key] = value
+			}
+		}
+		return result
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if options := listValue(schema[keyword]); len(options) > 0 {
+			option, err := d.resolve(options[0])
+			if err != nil {
+				return nil
+			}
+			return d.sample(option, depth+1)
+		}
+	}
+
+	schemaType := stringValue(schema["type"])
+	if schemaType == "" && schema["properties"] != nil {
+		schemaType = "object"
+	}
+
+	switch schemaType {
+	case "object":
+		result := map[string]interface{}{}
+		properties := mapValue(schema["properties"])
+		for _, name := range sortedKeys(properties) {
+			property, err := d.resolve(properties[name])
+			if err != nil || property == nil {
+				continue
+			}
+			result[name] = d.sample(property, depth+1)
+		}
+		return result
+	case "array":
+		items, err := d.resolve(schema["items"])
+		if err != nil || items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{d.sample(items, depth+1)}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		return stringSample(stringValue(schema["format"]))
+	default:
+		return nil
+	}
+}
+
+func stringSample(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// isBinarySchema reports whether a schema describes a file.
+func (d *document) isBinarySchema(schemaValue interface{}) bool {
+	schema, err := d.resolve(schemaValue)
+	if err != nil || schema == nil {
+		return false
+	}
+
+	format := stringValue(schema["format"])
+	return stringValue(schema["type"]) == "file" || format == "binary" || format == "base64"
+}
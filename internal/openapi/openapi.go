@@ -0,0 +1,597 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	baseURLVariable = "baseUrl"
+)
+
+var (
+	methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+	pathParameterRegex  = regexp.MustCompile(`{([^{}]+)}`)
+	unsafeVariableRegex = regexp.MustCompile(`[^A-Za-z0-9_.\-]+`)
+)
+
+type ImportResult struct {
+	Name string
+	// Requests have their Collection set to the tag of their operation (empty
+	// for operations without tags).
+	Requests  []*configs.RequestConfig
+	Variables map[string]string
+	Warnings  []string
+
+	usedNames map[string]bool
+}
+
+// Import creates a request config for each operation of an OpenAPI 3 or
+// Swagger 2 spec, in YAML or JSON. If server is not empty, it is used as the
+// domain of every request instead of the servers of the spec. The examples of
+// path parameters and the credentials of the security schemes are returned as
+// variables.
+func Import(data []byte, server string) (*ImportResult, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	root := mapValue(normalize(raw))
+	if root == nil {
+		return nil, fmt.Errorf("invalid spec: expected an object")
+	}
+
+	doc := &document{root: root}
+	switch {
+	case strings.HasPrefix(specVersion(root["openapi"]), "3."):
+	case specVersion(root["swagger"]) == "2.0":
+		doc.isSwagger = true
+	default:
+		return nil, fmt.Errorf("unsupported spec, only OpenAPI 3 and Swagger 2 are supported")
+	}
+
+	result := &ImportResult{
+		Requests:  make([]*configs.RequestConfig, 0),
+		Variables: map[string]string{},
+		Warnings:  make([]string, 0),
+		usedNames: map[string]bool{},
+	}
+
+	if title := stringValue(mapValue(root["info"])["title"]); title != "" {
+		result.Name = files.SafeFileName(title)
+	}
+
+	paths := mapValue(root["paths"])
+	for _, path := range sortedKeys(paths) {
+		pathItem, err := doc.resolve(paths[path])
+		if err != nil {
+			result.warn(path, err.Error())
+			continue
+		}
+
+		for _, method := range methods {
+			operation := mapValue(pathItem[method])
+			if operation == nil {
+				continue
+			}
+			result.importOperation(doc, path, method, pathItem, operation, server)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *ImportResult) warn(operation, message string) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", operation, message))
+}
+
+func (r *ImportResult) importOperation(
+	doc *document,
+	path, method string,
+	pathItem, operation map[string]interface{},
+	server string,
+) {
+	operationName := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+
+	reqConfig := &configs.RequestConfig{
+		Method:      strings.ToUpper(method),
+		Path:        pathParameterRegex.ReplaceAllString(path, "{{$1}}"),
+		QueryParams: map[string]string{},
+		Headers:     map[string]string{},
+	}
+
+	if tags := listValue(operation["tags"]); len(tags) > 0 {
+		reqConfig.Collection = files.SafeFileName(formatValue(tags[0]))
+	}
+	reqConfig.RequestName = r.requestName(reqConfig.Collection, operation, operationName)
+
+	reqConfig.Domain = server
+	if reqConfig.Domain == "" {
+		reqConfig.Domain = r.serverURL(doc, pathItem, operation, operationName)
+	}
+	reqConfig.Domain = strings.TrimRight(reqConfig.Domain, "/")
+
+	formParameters := make([]map[string]interface{}, 0)
+	for _, parameter := range r.parameters(doc, pathItem, operation, operationName) {
+		name := stringValue(parameter["name"])
+		example, hasExample := doc.parameterExample(parameter)
+		required, _ := parameter["required"].(bool)
+
+		switch stringValue(parameter["in"]) {
+		case "path":
+			if value, ok := r.Variables[name]; !ok || value == "" {
+				r.Variables[name] = formatValue(example)
+			}
+		case "query":
+			if required || hasExample {
+				reqConfig.QueryParams[name] = formatValue(example)
+			}
+		case "header":
+			if required || hasExample {
+				reqConfig.Headers[name] = formatValue(example)
+			}
+		case "cookie":
+			r.warn(operationName, fmt.Sprintf("cookie parameter %s was not imported", name))
+		case "body":
+			r.importSwaggerBody(doc, reqConfig, operation, parameter)
+		case "formData":
+			formParameters = append(formParameters, parameter)
+		}
+	}
+
+	if len(formParameters) > 0 {
+		r.importSwaggerForm(doc, reqConfig, operation, formParameters, operationName)
+	}
+
+	r.importSecurity(doc, reqConfig, operation, operationName)
+
+	reqConfigs := []*configs.RequestConfig{reqConfig}
+	if requestBody, err := doc.resolve(operation["requestBody"]); err != nil {
+		r.warn(operationName, err.Error())
+	} else if requestBody != nil {
+		reqConfigs = r.importRequestBodies(doc, reqConfig, requestBody, operationName)
+	}
+	r.Requests = append(r.Requests, reqConfigs...)
+}
+
+// requestName returns a unique name for the request of an operation, using
+// its operationId when it has one.
+func (r *ImportResult) requestName(collection string, operation map[string]interface{}, operationName string) string {
+	name := stringValue(operation["operationId"])
+	if name == "" {
+		name = stringValue(operation["summary"])
+	}
+	if name == "" {
+		name = operationName
+	}
+	return r.uniqueName(collection, files.SafeFileName(name))
+}
+
+// uniqueName returns the name, with a numeric suffix if a request of the
+// collection already has it.
+func (r *ImportResult) uniqueName(collection, name string) string {
+	uniqueName := name
+	for i := 2; r.usedNames[collection+"/"+uniqueName]; i++ {
+		uniqueName = fmt.Sprintf("%s-%d", name, i)
+	}
+	r.usedNames[collection+"/"+uniqueName] = true
+	return uniqueName
+}
+
+func (r *ImportResult) serverURL(doc *document, pathItem, operation map[string]interface{}, operationName string) string {
+	if doc.isSwagger {
+		host := stringValue(doc.root["host"])
+		basePath := stringValue(doc.root["basePath"])
+		if host == "" {
+			r.addBaseURLVariable(operationName)
+			return fmt.Sprintf("{{%s}}%s", baseURLVariable, basePath)
+		}
+
+		scheme := "https"
+		if schemes := listValue(doc.root["schemes"]); len(schemes) > 0 {
+			scheme = formatValue(schemes[0])
+		}
+		return fmt.Sprintf("%s://%s%s", scheme, host, basePath)
+	}
+
+	servers := listValue(operation["servers"])
+	if len(servers) == 0 {
+		servers = listValue(pathItem["servers"])
+	}
+	if len(servers) == 0 {
+		servers = listValue(doc.root["servers"])
+	}
+	if len(servers) == 0 {
+		r.addBaseURLVariable(operationName)
+		return fmt.Sprintf("{{%s}}", baseURLVariable)
+	}
+
+	server := mapValue(servers[0])
+	serverURL := stringValue(server["url"])
+	serverVariables := mapValue(server["variables"])
+	serverURL = pathParameterRegex.ReplaceAllStringFunc(serverURL, func(placeholder string) string {
+		variable := mapValue(serverVariables[placeholder[1:len(placeholder)-1]])
+		if value, ok := variable["default"]; ok {
+			return formatValue(value)
+		}
+		return placeholder
+	})
+
+	if !strings.Contains(serverURL, "://") {
+		r.addBaseURLVariable(operationName)
+		return fmt.Sprintf("{{%s}}%s", baseURLVariable, serverURL)
+	}
+	return serverURL
+}
+
+func (r *ImportResult) addBaseURLVariable(operationName string) {
+	if _, ok := r.Variables[baseURLVariable]; ok {
+		return
+	}
+	r.Variables[baseURLVariable] = ""
+	r.warn(operationName, fmt.Sprintf("the spec has no absolute server URL, set the {{%s}} variable", baseURLVariable))
+}
+
+// parameters returns the parameters of the operation, including the ones of
+// its path which it doesn't override.
+func (r *ImportResult) parameters(doc *document, pathItem, operation map[string]interface{}, operationName string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	indexes := map[string]int{}
+
+	for _, parameterValue := range append(listValue(pathItem["parameters"]), listValue(operation["parameters"])...) {
+		parameter, err := doc.resolve(parameterValue)
+		if err != nil {
+			r.warn(operationName, err.Error())
+			continue
+		}
+
+		key := stringValue(parameter["in"]) + ":" + stringValue(parameter["name"])
+		if index, ok := indexes[key]; ok {
+			result[index] = parameter
+			continue
+		}
+		indexes[key] = len(result)
+		result = append(result, parameter)
+	}
+	return result
+}
+
+// importRequestBodies imports a request for each media type of the request
+// body. The request of the preferred media type (JSON, then forms) keeps the
+// name of the operation, and the others are suffixed with their media type,
+// e.g. "createPet-xml".
+func (r *ImportResult) importRequestBodies(doc *document, reqConfig *configs.RequestConfig, requestBody map[string]interface{}, operationName string) []*configs.RequestConfig {
+	content := mapValue(requestBody["content"])
+	mediaTypes := sortedKeys(content)
+	if len(mediaTypes) == 0 {
+		return []*configs.RequestConfig{reqConfig}
+	}
+
+	preferred := preferredMediaType(mediaTypes)
+	reqConfigs := []*configs.RequestConfig{reqConfig}
+	r.importRequestBody(doc, reqConfig, preferred, mapValue(content[preferred]), operationName)
+
+	for _, mediaType := range mediaTypes {
+		if mediaType == preferred {
+			continue
+		}
+		other := copyRequest(reqConfig)
+		other.RequestName = r.uniqueName(other.Collection, reqConfig.RequestName+"-"+mediaTypeSuffix(mediaType))
+		r.importRequestBody(doc, other, mediaType, mapValue(content[mediaType]), operationName)
+		reqConfigs = append(reqConfigs, other)
+	}
+	return reqConfigs
+}
+
+func (r *ImportResult) importRequestBody(doc *document, reqConfig *configs.RequestConfig, chosen string, mediaType map[string]interface{}, operationName string) {
+	example, _ := doc.mediaTypeExample(mediaType)
+	reqConfig.ContentType = chosen
+
+	switch configs.ContentType(baseMediaType(chosen)) {
+	case configs.ContentTypeFormURLEncoded:
+		reqConfig.Body.FormURLEncoded = map[string]string{}
+		for key, value := range mapValue(example) {
+			reqConfig.Body.FormURLEncoded[key] = formatValue(value)
+		}
+	case configs.ContentTypeMultipartFormData:
+		schema, _ := doc.resolve(mediaType["schema"])
+		properties := mapValue(schema["properties"])
+		reqConfig.Body.MultipartBody = make([]*configs.MultipartBodyConfig, 0, len(properties))
+		for _, name := range sortedKeys(properties) {
+			if doc.isBinarySchema(properties[name]) {
+				reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, r.filePart(name, operationName))
+				continue
+			}
+			value := formatValue(mapValue(example)[name])
+			reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, &configs.MultipartBodyConfig{
+				Key:            name,
+				PlainTextValue: &value,
+			})
+		}
+	default:
+		if isBinaryMediaType(chosen) || doc.isBinarySchema(mediaType["schema"]) {
+			filePath := ""
+			reqConfig.Body.BinaryFileBody = &filePath
+			r.warn(operationName, "set the path of the file of the binary body")
+			return
+		}
+		r.setRawBody(reqConfig, chosen, example, operationName)
+	}
+}
+
+func (r *ImportResult) importSwaggerBody(doc *document, reqConfig *configs.RequestConfig, operation, parameter map[string]interface{}) {
+	reqConfig.ContentType = string(configs.ContentTypeJSON)
+	if consumes := r.swaggerConsumes(doc, operation); len(consumes) > 0 {
+		reqConfig.ContentType = preferredMediaType(consumes)
+	}
+
+	example, ok := parameter["x-example"]
+	if !ok {
+		schema, err := doc.resolve(parameter["schema"])
+		if err == nil && schema != nil {
+			example = doc.sample(schema, 0)
+		}
+	}
+	r.setRawBody(reqConfig, reqConfig.ContentType, example, "")
+}
+
+func (r *ImportResult) importSwaggerForm(
+	doc *document,
+	reqConfig *configs.RequestConfig,
+	operation map[string]interface{},
+	parameters []map[string]interface{},
+	operationName string,
+) {
+	isMultipart := false
+	for _, mediaType := range r.swaggerConsumes(doc, operation) {
+		isMultipart = isMultipart || baseMediaType(mediaType) == string(configs.ContentTypeMultipartFormData)
+	}
+	for _, parameter := range parameters {
+		isMultipart = isMultipart || stringValue(parameter["type"]) == "file"
+	}
+
+	if !isMultipart {
+		reqConfig.ContentType = string(configs.ContentTypeFormURLEncoded)
+		reqConfig.Body.FormURLEncoded = map[string]string{}
+		for _, parameter := range parameters {
+			example, _ := doc.parameterExample(parameter)
+			reqConfig.Body.FormURLEncoded[stringValue(parameter["name"])] = formatValue(example)
+		}
+		return
+	}
+
+	reqConfig.ContentType = string(configs.ContentTypeMultipartFormData)
+	reqConfig.Body.MultipartBody = make([]*configs.MultipartBodyConfig, 0, len(parameters))
+	for _, parameter := range parameters {
+		name := stringValue(parameter["name"])
+		if stringValue(parameter["type"]) == "file" {
+			reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, r.filePart(name, operationName))
+			continue
+		}
+
+		example, _ := doc.parameterExample(parameter)
+		value := formatValue(example)
+		reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, &configs.MultipartBodyConfig{
+			Key:            name,
+			PlainTextValue: &value,
+		})
+	}
+}
+
+func (r *ImportResult) swaggerConsumes(doc *document, operation map[string]interface{}) []string {
+	consumes := listValue(operation["consumes"])
+	if len(consumes) == 0 {
+		consumes = listValue(doc.root["consumes"])
+	}
+
+	result := make([]string, 0, len(consumes))
+	for _, mediaType := range consumes {
+		result = append(result, formatValue(mediaType))
+	}
+	return result
+}
+
+func (r *ImportResult) filePart(name, operationName string) *configs.MultipartBodyConfig {
+	filePath := ""
+	r.warn(operationName, fmt.Sprintf("set the path of the file of the %s part", name))
+	return &configs.MultipartBodyConfig{
+		Key:                 name,
+		BinaryFilePathValue: &filePath,
+	}
+}
+
+func (r *ImportResult) setRawBody(reqConfig *configs.RequestConfig, mediaType string, example interface{}, operationName string) {
+	body := ""
+	switch typed := example.(type) {
+	case nil:
+	case string:
+		body = typed
+		if isJSONMediaType(mediaType) {
+			quoted, _ := json.Marshal(typed)
+			body = string(quoted)
+		}
+	default:
+		if !isJSONMediaType(mediaType) && operationName != "" {
+			r.warn(operationName, fmt.Sprintf("the example of the %s body was written as JSON", mediaType))
+		}
+		marshalled, err := json.MarshalIndent(typed, "", "  ")
+		if err == nil {
+			body = string(marshalled)
+		}
+	}
+	reqConfig.Body.RawBody = &body
+}
+
+func (r *ImportResult) importSecurity(doc *document, reqConfig *configs.RequestConfig, operation map[string]interface{}, operationName string) {
+	security, hasSecurity := operation["security"]
+	if !hasSecurity {
+		security = doc.root["security"]
+	}
+
+	requirements := listValue(security)
+	if len(requirements) == 0 {
+		return
+	}
+
+	schemes := mapValue(mapValue(doc.root["components"])["securitySchemes"])
+	if doc.isSwagger {
+		schemes = mapValue(doc.root["securityDefinitions"])
+	}
+
+	// Only the first alternative of the requirements is needed to
+	// authenticate.
+	requirement := mapValue(requirements[0])
+	for _, schemeName := range sortedKeys(requirement) {
+		scheme, err := doc.resolve(schemes[schemeName])
+		if err != nil || scheme == nil {
+			r.warn(operationName, fmt.Sprintf("security scheme %s does not exist", schemeName))
+			continue
+		}
+
+		variable := unsafeVariableRegex.ReplaceAllString(schemeName, "_")
+		placeholder := fmt.Sprintf("{{%s}}", variable)
+		schemeType := stringValue(scheme["type"])
+		httpScheme := strings.ToLower(stringValue(scheme["scheme"]))
+
+		// A request has a single auth, so the other schemes required along
+		// with it are set as headers or query params.
+		var schemeAuth *auth.Auth
+		schemeVariables := []string{variable}
+		switch {
+		case schemeType == "apiKey" && stringValue(scheme["in"]) == "header":
+			schemeAuth = &auth.Auth{Type: auth.TypeAPIKey, Key: stringValue(scheme["name"]), Value: placeholder, In: auth.InHeader}
+			if reqConfig.Auth != nil {
+				reqConfig.Headers[schemeAuth.Key] = placeholder
+			}
+		case schemeType == "apiKey" && stringValue(scheme["in"]) == "query":
+			schemeAuth = &auth.Auth{Type: auth.TypeAPIKey, Key: stringValue(scheme["name"]), Value: placeholder, In: auth.InQuery}
+			if reqConfig.Auth != nil {
+				reqConfig.QueryParams[schemeAuth.Key] = placeholder
+			}
+		case schemeType == "http" && httpScheme == "bearer":
+			schemeAuth = &auth.Auth{Type: auth.TypeBearer, Token: placeholder}
+			if reqConfig.Auth != nil {
+				reqConfig.Headers["Authorization"] = "Bearer " + placeholder
+			}
+		case (schemeType == "http" && (httpScheme == "basic" || httpScheme == "digest")) || schemeType == "basic":
+			if reqConfig.Auth != nil {
+				r.warn(operationName, fmt.Sprintf("security scheme %s (%s) was not imported", schemeName, schemeType))
+				continue
+			}
+			schemeVariables = []string{variable + "_username", variable + "_password"}
+			schemeAuth = &auth.Auth{
+				Type:     auth.TypeBasic,
+				Username: fmt.Sprintf("{{%s}}", schemeVariables[0]),
+				Password: fmt.Sprintf("{{%s}}", schemeVariables[1]),
+			}
+			if httpScheme == "digest" {
+				schemeAuth.Type = auth.TypeDigest
+			}
+		default:
+			r.warn(operationName, fmt.Sprintf("security scheme %s (%s) was not imported", schemeName, schemeType))
+			continue
+		}
+
+		if reqConfig.Auth == nil {
+			reqConfig.Auth = schemeAuth
+		}
+
+		for _, schemeVariable := range schemeVariables {
+			if _, ok := r.Variables[schemeVariable]; !ok {
+				r.Variables[schemeVariable] = ""
+			}
+		}
+	}
+}
+
+// preferredMediaType chooses the media type of a body, preferring JSON and
+// then forms, since they can be best represented by a request config.
+func preferredMediaType(mediaTypes []string) string {
+	for _, matches := range []func(string) bool{
+		isJSONMediaType,
+		func(mediaType string) bool {
+			return baseMediaType(mediaType) == string(configs.ContentTypeFormURLEncoded)
+		},
+		func(mediaType string) bool {
+			return baseMediaType(mediaType) == string(configs.ContentTypeMultipartFormData)
+		},
+	} {
+		for _, mediaType := range mediaTypes {
+			if matches(mediaType) {
+				return mediaType
+			}
+		}
+	}
+	return mediaTypes[0]
+}
+
+// mediaTypeSuffix returns the subtype of the media type, without its
+// structured syntax suffix, e.g. "xml" for "application/xml" and
+// "vnd.api" for "application/vnd.api+json".
+func mediaTypeSuffix(mediaType string) string {
+	_, subtype, _ := strings.Cut(baseMediaType(mediaType), "/")
+	if base, _, found := strings.Cut(subtype, "+"); found && base != "" {
+		subtype = base
+	}
+	if subtype == "" || subtype == "*" {
+		subtype = "body"
+	}
+	return files.SafeFileName(subtype)
+}
+
+// copyRequest copies the request, without its body.
+func copyRequest(reqConfig *configs.RequestConfig) *configs.RequestConfig {
+	result := &configs.RequestConfig{
+		Collection:  reqConfig.Collection,
+		RequestName: reqConfig.RequestName,
+		Domain:      reqConfig.Domain,
+		Path:        reqConfig.Path,
+		Method:      reqConfig.Method,
+		QueryParams: make(map[string]string, len(reqConfig.QueryParams)),
+		Headers:     make(map[string]string, len(reqConfig.Headers)),
+	}
+	if reqConfig.Auth != nil {
+		requestAuth := *reqConfig.Auth
+		result.Auth = &requestAuth
+	}
+	for key, value := range reqConfig.QueryParams {
+		result.QueryParams[key] = value
+	}
+	for key, value := range reqConfig.Headers {
+		result.Headers[key] = value
+	}
+	return result
+}
+
+func baseMediaType(mediaType string) string {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return strings.ToLower(mediaType)
+	}
+	return parsed
+}
+
+func isJSONMediaType(mediaType string) bool {
+	base := baseMediaType(mediaType)
+	return base == string(configs.ContentTypeJSON) || strings.HasSuffix(base, "+json")
+}
+
+func isBinaryMediaType(mediaType string) bool {
+	base := baseMediaType(mediaType)
+	return base == string(configs.ContentTypeOctetStream) ||
+		strings.HasPrefix(base, "image/") ||
+		strings.HasPrefix(base, "audio/") ||
+		strings.HasPrefix(base, "video/") ||
+		base == "application/pdf" ||
+		base == "application/zip"
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"os"
+	"testing"
+)
+
+func TestImportUnquotedVersions(t *testing.T) {
+	tests := []struct {
+		spec   string
+		domain string
+		path   string
+		method string
+	}{
+		{spec: "testdata/swagger2.yaml", domain: "https://petstore.example.com/v1", path: "/pets/{{petId}}", method: "GET"},
+		{spec: "testdata/openapi3.yaml", domain: "https://petstore.example.com/v1", path: "/pets", method: "POST"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			data, err := os.ReadFile(test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Import(data, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Requests) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(result.Requests))
+			}
+
+			request := result.Requests[0]
+			if request.Domain != test.domain || request.Path != test.path || request.Method != test.method {
+				t.Errorf("unexpected request %s %s%s", request.Method, request.Domain, request.Path)
+			}
+		})
+	}
+}
+
+func TestSpecVersion(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: 2.0, expected: "2.0"},
+		{value: 3.0, expected: "3.0"},
+		{value: 3.1, expected: "3.1"},
+		{value: 2, expected: "2.0"},
+		{value: "3.0.3", expected: "3.0.3"},
+		{value: nil, expected: ""},
+	}
+
+	for _, test := range tests {
+		if version := specVersion(test.value); version != test.expected {
+			t.Errorf("specVersion(%v): expected %q, got %q", test.value, test.expected, version)
+		}
+	}
+}
+
+func TestImportUnsupportedVersion(t *testing.T) {
+	for _, spec := range []string{"swagger: 1.2\n", "openapi: 4.0\n", "info: {}\n"} {
+		if _, err := Import([]byte(spec), ""); err == nil {
+			t.Errorf("expected %q to be unsupported", spec)
+		}
+	}
+}
+
+func TestImportRequestBodyMediaTypes(t *testing.T) {
+	data, err := os.ReadFile("testdata/media-types.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Import(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", result.Warnings)
+	}
+
+	expected := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "createPet", contentType: "application/json"},
+		{name: "createPet-x-www-form-urlencoded", contentType: "application/x-www-form-urlencoded"},
+		{name: "createPet-xml", contentType: "application/xml"},
+	}
+	if len(result.Requests) != len(expected) {
+		t.Fatalf("expected %d requests, got %d", len(expected), len(result.Requests))
+	}
+
+	for i, request := range result.Requests {
+		if request.RequestName != expected[i].name || request.ContentType != expected[i].contentType {
+			t.Errorf("expected %s (%s), got %s (%s)", expected[i].name, expected[i].contentType, request.RequestName, request.ContentType)
+		}
+		if request.Auth == nil || request.Auth.Token != "{{token}}" {
+			t.Errorf("expected %s to have the bearer auth", request.RequestName)
+		}
+	}
+
+	if body := result.Requests[0].Body.RawBody; body == nil || *body != "{\n  \"name\": \"Rex\"\n}" {
+		t.Errorf("unexpected JSON body %v", body)
+	}
+	if form := result.Requests[1].Body.FormURLEncoded; form["name"] != "Rex" {
+		t.Errorf("unexpected form %v", form)
+	}
+	if body := result.Requests[2].Body.RawBody; body == nil || *body != "<pet><name>Rex</name></pet>" {
+		t.Errorf("unexpected XML body %v", body)
+	}
+}
@@ -0,0 +1,265 @@
+package postman
+
+import (
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+)
+
+var contentTypeRawLanguages = map[string]string{
+	string(configs.ContentTypeJSON): "json",
+	string(configs.ContentTypeXML):  "xml",
+	"text/xml":                      "xml",
+	"text/html":                     "html",
+	"text/plain":                    "text",
+	"application/javascript":        "javascript",
+}
+
+// Export translates request configs into a Postman Collection v2.1. The
+// Collection of each request must be the path of its folder, relative to the
+// exported collection (empty for requests at the root), so sub-collections
+// become folders. The variables are exported as collection variables.
+func Export(name string, reqConfigs []*configs.RequestConfig, variables map[string]string) *Collection {
+	collection := &Collection{
+		Info: Info{
+			Name:   name,
+			Schema: SchemaV21,
+		},
+		Item: make([]Item, 0),
+	}
+
+	for _, reqConfig := range reqConfigs {
+		items := &collection.Item
+		if reqConfig.Collection != "" {
+			for _, folder := range strings.Split(filepath.ToSlash(reqConfig.Collection), "/") {
+				items = folderItems(items, folder)
+			}
+		}
+
+		*items = append(*items, Item{
+			Name:    reqConfig.RequestName,
+			Request: exportRequest(reqConfig),
+		})
+	}
+
+	for _, key := range sortedKeys(variables) {
+		collection.Variable = append(collection.Variable, Variable{
+			Key:   key,
+			Value: variables[key],
+			Type:  "string",
+		})
+	}
+
+	return collection
+}
+
+// folderItems returns the items of the folder with the given name, creating
+// the folder if it does not exist yet.
+func folderItems(items *[]Item, name string) *[]Item {
+	for i := range *items {
+		if (*items)[i].IsFolder() && (*items)[i].Name == name {
+			return &(*items)[i].Item
+		}
+	}
+
+	*items = append(*items, Item{Name: name, Item: make([]Item, 0)})
+	return &(*items)[len(*items)-1].Item
+}
+
+func exportRequest(reqConfig *configs.RequestConfig) *Request {
+	request := &Request{
+		Method: reqConfig.Method,
+		Header: make([]KeyValue, 0),
+		URL:    exportURL(reqConfig),
+	}
+
+	isMultipart := configs.ContentType(reqConfig.ContentType) == configs.ContentTypeMultipartFormData ||
+		len(reqConfig.Body.MultipartBody) > 0
+	if reqConfig.ContentType != "" && !isMultipart {
+		// Postman sets the boundary of multipart requests by itself.
+		request.Header = append(request.Header, KeyValue{Key: "Content-Type", Value: reqConfig.ContentType})
+	}
+	for _, key := range sortedKeys(reqConfig.Headers) {
+		request.Header = append(request.Header, KeyValue{Key: key, Value: reqConfig.Headers[key]})
+	}
+
+	request.Body = exportBody(reqConfig)
+	request.Auth = exportAuth(reqConfig.Auth)
+	return request
+}
+
+func exportAuth(requestAuth *auth.Auth) *Auth {
+	if requestAuth == nil {
+		return nil
+	}
+
+	attribute := func(key, value string) AuthAttribute {
+		return AuthAttribute{Key: key, Value: value, Type: "string"}
+	}
+
+	switch requestAuth.Type {
+	case auth.TypeBasic:
+		return &Auth{Type: "basic", Basic: []AuthAttribute{
+			attribute("username", requestAuth.Username),
+			attribute("password", requestAuth.Password),
+		}}
+	case auth.TypeDigest:
+		return &Auth{Type: "digest", Digest: []AuthAttribute{
+			attribute("username", requestAuth.Username),
+			attribute("password", requestAuth.Password),
+		}}
+	case auth.TypeBearer:
+		return &Auth{Type: "bearer", Bearer: []AuthAttribute{
+			attribute("token", requestAuth.Token),
+		}}
+	case auth.TypeAPIKey:
+		in := requestAuth.In
+		if in == "" {
+			in = auth.InHeader
+		}
+		return &Auth{Type: "apikey", APIKey: []AuthAttribute{
+			attribute("key", requestAuth.Key),
+			attribute("value", requestAuth.Value),
+			attribute("in", in),
+		}}
+	case auth.TypeOAuth2:
+		return exportOAuth2(requestAuth)
+	case auth.TypeAWSSigV4:
+		return &Auth{Type: "awsv4", AWSV4: []AuthAttribute{
+			attribute("accessKey", requestAuth.AccessKey),
+			attribute("secretKey", requestAuth.SecretKey),
+			attribute("sessionToken", requestAuth.SessionToken),
+			attribute("region", requestAuth.Region),
+			attribute("service", requestAuth.Service),
+		}}
+	}
+	return nil
+}
+
+// exportOAuth2 exports the grant types which Postman supports. Refresh
+// tokens are requested by Postman by itself.
+func exportOAuth2(requestAuth *auth.Auth) *Auth {
+	attribute := func(key, value string) AuthAttribute {
+		return AuthAttribute{Key: key, Value: value, Type: "string"}
+	}
+
+	grantType := requestAuth.GrantType
+	switch grantType {
+	case auth.GrantTypePassword:
+		grantType = "password_credentials"
+	case auth.GrantTypeRefreshToken:
+		return nil
+	}
+
+	attributes := []AuthAttribute{
+		attribute("grant_type", grantType),
+		attribute("accessTokenUrl", requestAuth.TokenURL),
+		attribute("clientId", requestAuth.ClientID),
+		attribute("clientSecret", requestAuth.ClientSecret),
+		attribute("scope", strings.Join(requestAuth.Scopes, " ")),
+	}
+	if requestAuth.ClientAuth != "" {
+		attributes = append(attributes, attribute("client_authentication", requestAuth.ClientAuth))
+	}
+	if grantType == "password_credentials" {
+		attributes = append(attributes, attribute("username", requestAuth.Username), attribute("password", requestAuth.Password))
+	}
+	return &Auth{Type: "oauth2", OAuth2: attributes}
+}
+
+func exportURL(reqConfig *configs.RequestConfig) URL {
+	domain := strings.TrimRight(reqConfig.Domain, "/")
+	path := strings.Trim(reqConfig.Path, "/")
+
+	result := URL{
+		Raw:   domain,
+		Query: make([]KeyValue, 0),
+	}
+	if path != "" {
+		result.Raw += "/" + path
+		result.Path = strings.Split(path, "/")
+	}
+
+	host := domain
+	if scheme, rest, found := strings.Cut(domain, "://"); found {
+		result.Protocol = scheme
+		host = rest
+	}
+	if hostname, port, found := strings.Cut(host, ":"); found && !strings.Contains(host, "{{") {
+		host = hostname
+		result.Port = port
+	}
+	if strings.HasPrefix(host, "{{") {
+		result.Host = []string{host}
+	} else {
+		result.Host = strings.Split(host, ".")
+	}
+
+	queryParts := make([]string, 0, len(reqConfig.QueryParams))
+	for _, key := range sortedKeys(reqConfig.QueryParams) {
+		result.Query = append(result.Query, KeyValue{Key: key, Value: reqConfig.QueryParams[key]})
+		queryParts = append(queryParts, key+"="+reqConfig.QueryParams[key])
+	}
+	if len(queryParts) > 0 {
+		result.Raw += "?" + strings.Join(queryParts, "&")
+	}
+
+	return result
+}
+
+func exportBody(reqConfig *configs.RequestConfig) *Body {
+	body := reqConfig.Body
+
+	switch {
+	case body.RawBody != nil:
+		result := &Body{Mode: "raw", Raw: *body.RawBody}
+		mediaType, _, err := mime.ParseMediaType(reqConfig.ContentType)
+		if language, ok := contentTypeRawLanguages[mediaType]; err == nil && ok {
+			result.Options = &BodyOptions{Raw: &RawOptions{Language: language}}
+		}
+		return result
+	case body.BinaryFileBody != nil:
+		return &Body{Mode: "file", File: &File{Src: *body.BinaryFileBody}}
+	case len(body.MultipartBody) > 0:
+		result := &Body{Mode: "formdata", FormData: make([]FormParam, 0, len(body.MultipartBody))}
+		// The parts are exported as they're sent, so text values take
+		// precedence over files.
+		for _, part := range body.MultipartBody {
+			if part.PlainTextValue != nil {
+				result.FormData = append(result.FormData, FormParam{
+					Key:   part.Key,
+					Value: *part.PlainTextValue,
+					Type:  "text",
+				})
+			} else if part.BinaryFilePathValue != nil && *part.BinaryFilePathValue != "" {
+				result.FormData = append(result.FormData, FormParam{
+					Key:  part.Key,
+					Src:  *part.BinaryFilePathValue,
+					Type: "file",
+				})
+			}
+		}
+		return result
+	case len(body.FormURLEncoded) > 0:
+		result := &Body{Mode: "urlencoded", URLEncoded: make([]KeyValue, 0, len(body.FormURLEncoded))}
+		for _, key := range sortedKeys(body.FormURLEncoded) {
+			result.URLEncoded = append(result.URLEncoded, KeyValue{Key: key, Value: body.FormURLEncoded[key]})
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,403 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/auth"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/files"
+)
+
+var (
+	pathVariableRegex    = regexp.MustCompile(`^:([A-Za-z0-9_\-.]+)$`)
+	dynamicVariableRegex = regexp.MustCompile(`{{\s*\$[^{}]*}}`)
+)
+
+var rawLanguageContentTypes = map[string]configs.ContentType{
+	"json":       configs.ContentTypeJSON,
+	"xml":        configs.ContentTypeXML,
+	"html":       "text/html",
+	"text":       "text/plain",
+	"javascript": "application/javascript",
+}
+
+type ImportResult struct {
+	Name string
+	// Requests have their Collection set to the path of their folder,
+	// relative to the collection (empty for requests at the root).
+	Requests  []*configs.RequestConfig
+	Variables map[string]string
+	Warnings  []string
+}
+
+// Import translates a Postman Collection v2.1 into request configs. Folders
+// become sub-collections, and the collection and path variables are returned
+// so they can be saved into an environment.
+func Import(data []byte) (*ImportResult, error) {
+	var collection Collection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %s, export it as v2.1", collection.Info.Schema)
+	}
+
+	result := &ImportResult{
+		Requests:  make([]*configs.RequestConfig, 0),
+		Variables: map[string]string{},
+		Warnings:  make([]string, 0),
+	}
+
+	if collection.Info.Name != "" {
+		result.Name = files.SafeFileName(collection.Info.Name)
+	}
+
+	for _, variable := range collection.Variable {
+		result.Variables[variable.Key] = variable.StringValue()
+	}
+
+	if len(collection.Event) > 0 {
+		result.warn("", "collection scripts were not imported")
+	}
+
+	result.importItems(collection.Item, "", collection.Auth)
+	return result, nil
+}
+
+func (r *ImportResult) warn(itemPath, message string) {
+	if itemPath == "" {
+		r.Warnings = append(r.Warnings, message)
+		return
+	}
+	r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", itemPath, message))
+}
+
+func (r *ImportResult) importItems(items []Item, folder string, inheritedAuth *Auth) {
+	usedNames := map[string]int{}
+
+	for _, item := range items {
+		name := files.SafeFileName(item.Name)
+		usedNames[name]++
+		if usedNames[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, usedNames[name])
+		}
+		itemPath := filepath.Join(folder, name)
+
+		if len(item.Event) > 0 {
+			r.warn(itemPath, "scripts were not imported")
+		}
+
+		if item.IsFolder() {
+			auth := inheritedAuth
+			if item.Auth != nil {
+				auth = item.Auth
+			}
+			r.importItems(item.Item, itemPath, auth)
+			continue
+		}
+
+		auth := inheritedAuth
+		if item.Request.Auth != nil {
+			auth = item.Request.Auth
+		}
+
+		reqConfig := r.importRequest(item.Request, itemPath, auth)
+		reqConfig.Collection = folder
+		reqConfig.RequestName = name
+		r.Requests = append(r.Requests, reqConfig)
+	}
+}
+
+func (r *ImportResult) importRequest(request *Request, itemPath string, auth *Auth) *configs.RequestConfig {
+	reqConfig := &configs.RequestConfig{
+		Method:      strings.ToUpper(request.Method),
+		QueryParams: map[string]string{},
+		Headers:     map[string]string{},
+	}
+	if reqConfig.Method == "" {
+		reqConfig.Method = "GET"
+	}
+
+	r.importURL(reqConfig, request.URL)
+
+	for _, header := range request.Header {
+		if header.Disabled {
+			continue
+		}
+		if strings.EqualFold(header.Key, "Content-Type") {
+			reqConfig.ContentType = header.Value
+			continue
+		}
+		reqConfig.Headers[header.Key] = header.Value
+	}
+
+	if request.Body != nil && !request.Body.Disabled {
+		r.importBody(reqConfig, request.Body, itemPath)
+	}
+
+	if auth != nil {
+		r.importAuth(reqConfig, auth, itemPath)
+	}
+
+	r.warnDynamicVariables(reqConfig, itemPath)
+	return reqConfig
+}
+
+func (r *ImportResult) importURL(reqConfig *configs.RequestConfig, postmanURL URL) {
+	raw := postmanURL.Raw
+	if raw == "" {
+		raw = buildRawURL(postmanURL)
+	}
+
+	base, rawQuery, _ := strings.Cut(raw, "?")
+	base, _, _ = strings.Cut(base, "#")
+
+	hostStart := 0
+	if index := strings.Index(base, "://"); index >= 0 {
+		hostStart = index + len("://")
+	}
+
+	domain, path := base, ""
+	if index := strings.Index(base[hostStart:], "/"); index >= 0 {
+		domain, path = base[:hostStart+index], base[hostStart+index:]
+	}
+
+	// Postman defaults to http when the URL has no scheme, but the domain
+	// may also be a variable that already contains it.
+	if hostStart == 0 && !strings.HasPrefix(domain, "{{") {
+		domain = "http://" + domain
+	}
+
+	// Path variables (/users/:id) are translated to placeholders, with their
+	// values added to the variables.
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		match := pathVariableRegex.FindStringSubmatch(segment)
+		if match == nil {
+			continue
+		}
+		segments[i] = fmt.Sprintf("{{%s}}", match[1])
+		if _, ok := r.Variables[match[1]]; !ok {
+			r.Variables[match[1]] = ""
+		}
+	}
+	for _, variable := range postmanURL.Variable {
+		r.Variables[variable.Key] = variable.StringValue()
+	}
+
+	reqConfig.Domain = domain
+	reqConfig.Path = strings.Join(segments, "/")
+
+	if postmanURL.Query != nil {
+		for _, param := range postmanURL.Query {
+			if !param.Disabled {
+				reqConfig.QueryParams[param.Key] = param.Value
+			}
+		}
+		return
+	}
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		unescapedKey, err := url.QueryUnescape(key)
+		if err == nil {
+			key = unescapedKey
+		}
+		unescapedValue, err := url.QueryUnescape(value)
+		if err == nil {
+			value = unescapedValue
+		}
+		reqConfig.QueryParams[key] = value
+	}
+}
+
+func (r *ImportResult) importBody(reqConfig *configs.RequestConfig, body *Body, itemPath string) {
+	switch body.Mode {
+	case "raw":
+		raw := body.Raw
+		reqConfig.Body.RawBody = &raw
+		if reqConfig.ContentType == "" && body.Options != nil && body.Options.Raw != nil {
+			reqConfig.ContentType = string(rawLanguageContentTypes[body.Options.Raw.Language])
+		}
+	case "urlencoded":
+		reqConfig.ContentType = string(configs.ContentTypeFormURLEncoded)
+		reqConfig.Body.FormURLEncoded = map[string]string{}
+		for _, param := range body.URLEncoded {
+			if !param.Disabled {
+				reqConfig.Body.FormURLEncoded[param.Key] = param.Value
+			}
+		}
+	case "formdata":
+		reqConfig.ContentType = string(configs.ContentTypeMultipartFormData)
+		reqConfig.Body.MultipartBody = make([]*configs.MultipartBodyConfig, 0)
+		for _, param := range body.FormData {
+			if param.Disabled {
+				continue
+			}
+
+			if param.Type != "file" {
+				value := param.Value
+				reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, &configs.MultipartBodyConfig{
+					Key:            param.Key,
+					PlainTextValue: &value,
+				})
+				continue
+			}
+
+			sources := param.Sources()
+			if len(sources) == 0 {
+				r.warn(itemPath, fmt.Sprintf("form data file %q has no file selected", param.Key))
+			}
+			for _, source := range sources {
+				reqConfig.Body.MultipartBody = append(reqConfig.Body.MultipartBody, &configs.MultipartBodyConfig{
+					Key:                 param.Key,
+					BinaryFilePathValue: &source,
+				})
+			}
+		}
+	case "file":
+		if body.File == nil || body.File.Src == "" {
+			r.warn(itemPath, "binary body has no file selected")
+			return
+		}
+		src := body.File.Src
+		reqConfig.Body.BinaryFileBody = &src
+		if reqConfig.ContentType == "" {
+			reqConfig.ContentType = string(configs.ContentTypeOctetStream)
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		payload := map[string]interface{}{"query": body.GraphQL.Query}
+		if strings.TrimSpace(body.GraphQL.Variables) != "" {
+			payload["variables"] = json.RawMessage(body.GraphQL.Variables)
+		}
+		graphQLBody, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			r.warn(itemPath, fmt.Sprintf("graphql body was not imported: %s", err))
+			return
+		}
+		raw := string(graphQLBody)
+		reqConfig.Body.RawBody = &raw
+		reqConfig.ContentType = string(configs.ContentTypeJSON)
+	case "":
+	default:
+		r.warn(itemPath, fmt.Sprintf("body mode %q was not imported", body.Mode))
+	}
+}
+
+func (r *ImportResult) importAuth(reqConfig *configs.RequestConfig, postmanAuth *Auth, itemPath string) {
+	switch postmanAuth.Type {
+	case "noauth", "":
+	case "bearer":
+		reqConfig.Auth = &auth.Auth{
+			Type:  auth.TypeBearer,
+			Token: Attribute(postmanAuth.Bearer, "token"),
+		}
+	case "basic":
+		reqConfig.Auth = &auth.Auth{
+			Type:     auth.TypeBasic,
+			Username: Attribute(postmanAuth.Basic, "username"),
+			Password: Attribute(postmanAuth.Basic, "password"),
+		}
+	case "digest":
+		reqConfig.Auth = &auth.Auth{
+			Type:     auth.TypeDigest,
+			Username: Attribute(postmanAuth.Digest, "username"),
+			Password: Attribute(postmanAuth.Digest, "password"),
+		}
+	case "apikey":
+		reqConfig.Auth = &auth.Auth{
+			Type:  auth.TypeAPIKey,
+			Key:   Attribute(postmanAuth.APIKey, "key"),
+			Value: Attribute(postmanAuth.APIKey, "value"),
+			In:    auth.InHeader,
+		}
+		if Attribute(postmanAuth.APIKey, "in") == "query" {
+			reqConfig.Auth.In = auth.InQuery
+		}
+	case "oauth2":
+		r.importOAuth2(reqConfig, postmanAuth, itemPath)
+	case "awsv4":
+		reqConfig.Auth = &auth.Auth{
+			Type:         auth.TypeAWSSigV4,
+			AccessKey:    Attribute(postmanAuth.AWSV4, "accessKey"),
+			SecretKey:    Attribute(postmanAuth.AWSV4, "secretKey"),
+			SessionToken: Attribute(postmanAuth.AWSV4, "sessionToken"),
+			Region:       Attribute(postmanAuth.AWSV4, "region"),
+			Service:      Attribute(postmanAuth.AWSV4, "service"),
+		}
+	default:
+		r.warn(itemPath, fmt.Sprintf("%s auth was not imported", postmanAuth.Type))
+	}
+}
+
+// importOAuth2 imports the grant types which don't need a browser.
+func (r *ImportResult) importOAuth2(reqConfig *configs.RequestConfig, postmanAuth *Auth, itemPath string) {
+	requestAuth := &auth.Auth{
+		Type:         auth.TypeOAuth2,
+		TokenURL:     Attribute(postmanAuth.OAuth2, "accessTokenUrl"),
+		ClientID:     Attribute(postmanAuth.OAuth2, "clientId"),
+		ClientSecret: Attribute(postmanAuth.OAuth2, "clientSecret"),
+		Scopes:       strings.Fields(Attribute(postmanAuth.OAuth2, "scope")),
+		ClientAuth:   Attribute(postmanAuth.OAuth2, "client_authentication"),
+	}
+
+	switch grantType := Attribute(postmanAuth.OAuth2, "grant_type"); grantType {
+	case "client_credentials":
+		requestAuth.GrantType = auth.GrantTypeClientCredentials
+	case "password_credentials":
+		requestAuth.GrantType = auth.GrantTypePassword
+		requestAuth.Username = Attribute(postmanAuth.OAuth2, "username")
+		requestAuth.Password = Attribute(postmanAuth.OAuth2, "password")
+	default:
+		r.warn(itemPath, fmt.Sprintf("oauth2 auth with grant type %q was not imported", grantType))
+		return
+	}
+	reqConfig.Auth = requestAuth
+}
+
+// warnDynamicVariables reports Postman dynamic variables, like {{$guid}},
+// which have no equivalent in httpmate.
+func (r *ImportResult) warnDynamicVariables(reqConfig *configs.RequestConfig, itemPath string) {
+	marshalled, err := json.Marshal(reqConfig)
+	if err != nil {
+		return
+	}
+
+	found := map[string]bool{}
+	for _, match := range dynamicVariableRegex.FindAllString(string(marshalled), -1) {
+		if !found[match] {
+			found[match] = true
+			r.warn(itemPath, fmt.Sprintf("dynamic variable %s has no equivalent", match))
+		}
+	}
+}
+
+func buildRawURL(postmanURL URL) string {
+	var b strings.Builder
+	if postmanURL.Protocol != "" {
+		b.WriteString(postmanURL.Protocol)
+		b.WriteString("://")
+	}
+	b.WriteString(strings.Join(postmanURL.Host, "."))
+	if postmanURL.Port != "" {
+		b.WriteString(":")
+		b.WriteString(postmanURL.Port)
+	}
+	if len(postmanURL.Path) > 0 {
+		b.WriteString("/")
+		b.WriteString(strings.Join(postmanURL.Path, "/"))
+	}
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	SchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+)
+
+// Collection is a Postman Collection v2.1, as described in SchemaV21. Only
+// the fields which have an equivalent in httpmate are mapped.
+type Collection struct {
+	Info     Info              `json:"info"`
+	Item     []Item            `json:"item"`
+	Auth     *Auth             `json:"auth,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+	Variable []Variable        `json:"variable,omitempty"`
+}
+
+type Info struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+// Item is either a folder (with its own items) or a request.
+type Item struct {
+	Name     string            `json:"name"`
+	Item     []Item            `json:"item,omitempty"`
+	Request  *Request          `json:"request,omitempty"`
+	Auth     *Auth             `json:"auth,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+	Response []json.RawMessage `json:"response,omitempty"`
+}
+
+func (i *Item) IsFolder() bool {
+	return i.Request == nil
+}
+
+type Request struct {
+	Method string     `json:"method"`
+	Header []KeyValue `json:"header"`
+	URL    URL        `json:"url"`
+	Body   *Body      `json:"body,omitempty"`
+	Auth   *Auth      `json:"auth,omitempty"`
+}
+
+// UnmarshalJSON supports requests defined only by their URL.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var rawURL string
+	if err := json.Unmarshal(data, &rawURL); err == nil {
+		*r = Request{Method: "GET", URL: URL{Raw: rawURL}}
+		return nil
+	}
+
+	type request Request
+	var result request
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*r = Request(result)
+	return nil
+}
+
+type URL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol,omitempty"`
+	Host     []string   `json:"host,omitempty"`
+	Port     string     `json:"port,omitempty"`
+	Path     []string   `json:"path,omitempty"`
+	Query    []KeyValue `json:"query,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON supports URLs defined as a plain string.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var rawURL string
+	if err := json.Unmarshal(data, &rawURL); err == nil {
+		*u = URL{Raw: rawURL}
+		return nil
+	}
+
+	type postmanURL URL
+	var result postmanURL
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*u = URL(result)
+	return nil
+}
+
+type KeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type Variable struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+// StringValue returns the value of the variable as a string.
+func (v Variable) StringValue() string {
+	return stringValue(v.Value)
+}
+
+type Body struct {
+	Mode       string       `json:"mode"`
+	Raw        string       `json:"raw,omitempty"`
+	URLEncoded []KeyValue   `json:"urlencoded,omitempty"`
+	FormData   []FormParam  `json:"formdata,omitempty"`
+	File       *File        `json:"file,omitempty"`
+	GraphQL    *GraphQL     `json:"graphql,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty"`
+	Disabled   bool         `json:"disabled,omitempty"`
+}
+
+type FormParam struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value,omitempty"`
+	Src         interface{} `json:"src,omitempty"`
+	Type        string      `json:"type"`
+	ContentType string      `json:"contentType,omitempty"`
+	Disabled    bool        `json:"disabled,omitempty"`
+}
+
+// Sources returns the paths of the files of a "file" form param, which can be
+// a single path or a list of paths.
+func (f FormParam) Sources() []string {
+	switch src := f.Src.(type) {
+	case string:
+		return []string{src}
+	case []interface{}:
+		result := make([]string, 0, len(src))
+		for _, value := range src {
+			result = append(result, stringValue(value))
+		}
+		return result
+	default:
+		return []string{}
+	}
+}
+
+type File struct {
+	Src string `json:"src"`
+}
+
+type GraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type BodyOptions struct {
+	Raw *RawOptions `json:"raw,omitempty"`
+}
+
+type RawOptions struct {
+	Language string `json:"language"`
+}
+
+type Auth struct {
+	Type   string          `json:"type"`
+	Basic  []AuthAttribute `json:"basic,omitempty"`
+	Bearer []AuthAttribute `json:"bearer,omitempty"`
+	APIKey []AuthAttribute `json:"apikey,omitempty"`
+	Digest []AuthAttribute `json:"digest,omitempty"`
+	OAuth2 []AuthAttribute `json:"oauth2,omitempty"`
+	AWSV4  []AuthAttribute `json:"awsv4,omitempty"`
+}
+
+type AuthAttribute struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+// Attribute returns the value of an attribute of the auth method, e.g. the
+// "token" of a bearer auth.
+func Attribute(attributes []AuthAttribute, key string) string {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return stringValue(attribute.Value)
+		}
+	}
+	return ""
+}
+
+func stringValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,95 @@
+package pretty
+
+import (
+	"strings"
+)
+
+// HTML colours the tags, attributes and comments of the document. Since HTML
+// documents are often not well-formed, they are not indented.
+func HTML(data []byte, theme *Theme) []byte {
+	if theme == nil {
+		return data
+	}
+
+	html := string(data)
+	var out strings.Builder
+	for len(html) > 0 {
+		start := strings.Index(html, "<")
+		if start < 0 {
+			out.WriteString(html)
+			break
+		}
+		out.WriteString(html[:start])
+		html = html[start:]
+
+		if strings.HasPrefix(html, "<!--") {
+			end := strings.Index(html, "-->")
+			if end < 0 {
+				end = len(html)
+			} else {
+				end += len("-->")
+			}
+			out.WriteString(theme.paint(theme.Comment, html[:end]))
+			html = html[end:]
+			continue
+		}
+
+		end := strings.Index(html, ">")
+		if len(html) < 2 || !isTagStart(html[1]) || end < 0 {
+			// Not a tag, e.g. a "<" in a script.
+			out.WriteString("<")
+			html = html[1:]
+			continue
+		}
+
+		out.WriteString(htmlTag(html[:end+1], theme))
+		html = html[end+1:]
+	}
+	return []byte(out.String())
+}
+
+func isTagStart(c byte) bool {
+	return c == '/' || c == '!' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// htmlTag colours a tag, from its "<" to its ">".
+func htmlTag(tag string, theme *Theme) string {
+	nameEnd := strings.IndexAny(tag, " \t\r\n>")
+	if strings.HasSuffix(tag[:nameEnd], "/") {
+		nameEnd--
+	}
+
+	var out strings.Builder
+	out.WriteString(theme.paint(theme.Tag, tag[:nameEnd]))
+
+	rest := tag[nameEnd : len(tag)-1]
+	closing := ""
+	if strings.HasSuffix(rest, "/") {
+		rest, closing = rest[:len(rest)-1], "/"
+	}
+
+	for len(rest) > 0 {
+		switch c := rest[0]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '=':
+			out.WriteByte(c)
+			rest = rest[1:]
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(rest[1:], c)
+			if end < 0 {
+				end = len(rest) - 2
+			}
+			out.WriteString(theme.paint(theme.String, rest[:end+2]))
+			rest = rest[end+2:]
+		default:
+			end := strings.IndexAny(rest, " \t\r\n=")
+			if end < 0 {
+				end = len(rest)
+			}
+			out.WriteString(theme.paint(theme.Attribute, rest[:end]))
+			rest = rest[end:]
+		}
+	}
+
+	out.WriteString(theme.paint(theme.Tag, closing+">"))
+	return out.String()
+}
@@ -0,0 +1,120 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const indent = "  "
+
+// JSON indents the JSON document, keeping the order of the keys and the
+// numbers as they are.
+func JSON(data []byte, theme *Theme) ([]byte, error) {
+	theme = orNone(theme)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	if err := writeJSONValue(&out, decoder, theme, 0); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+	return out.Bytes(), nil
+}
+
+func writeJSONValue(out *bytes.Buffer, decoder *json.Decoder, theme *Theme, depth int) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			return writeJSONObject(out, decoder, theme, depth)
+		}
+		return writeJSONArray(out, decoder, theme, depth)
+	case string:
+		out.WriteString(theme.paint(theme.String, quote(value)))
+	case json.Number:
+		out.WriteString(theme.paint(theme.Number, value.String()))
+	case bool:
+		out.WriteString(theme.paint(theme.Literal, fmt.Sprint(value)))
+	case nil:
+		out.WriteString(theme.paint(theme.Literal, "null"))
+	}
+	return nil
+}
+
+func writeJSONObject(out *bytes.Buffer, decoder *json.Decoder, theme *Theme, depth int) error {
+	out.WriteString(theme.paint(theme.Punctuation, "{"))
+	if !decoder.More() {
+		decoder.Token()
+		out.WriteString(theme.paint(theme.Punctuation, "}"))
+		return nil
+	}
+
+	for first := true; decoder.More(); first = false {
+		if !first {
+			out.WriteString(theme.paint(theme.Punctuation, ","))
+		}
+		out.WriteString("\n" + strings.Repeat(indent, depth+1))
+
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		out.WriteString(theme.paint(theme.Key, quote(key.(string))))
+		out.WriteString(theme.paint(theme.Punctuation, ":") + " ")
+
+		if err := writeJSONValue(out, decoder, theme, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	out.WriteString("\n" + strings.Repeat(indent, depth) + theme.paint(theme.Punctuation, "}"))
+	return nil
+}
+
+func writeJSONArray(out *bytes.Buffer, decoder *json.Decoder, theme *Theme, depth int) error {
+	out.WriteString(theme.paint(theme.Punctuation, "["))
+	if !decoder.More() {
+		decoder.Token()
+		out.WriteString(theme.paint(theme.Punctuation, "]"))
+		return nil
+	}
+
+	for first := true; decoder.More(); first = false {
+		if !first {
+			out.WriteString(theme.paint(theme.Punctuation, ","))
+		}
+		out.WriteString("\n" + strings.Repeat(indent, depth+1))
+
+		if err := writeJSONValue(out, decoder, theme, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	out.WriteString("\n" + strings.Repeat(indent, depth) + theme.paint(theme.Punctuation, "]"))
+	return nil
+}
+
+// quote encodes the string as JSON, without escaping HTML characters.
+func quote(value string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,134 @@
+package pretty
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"sort"
+	"strings"
+)
+
+const reset = "\033[0m"
+
+// Theme is the ANSI colour of each kind of token. Empty colours are not
+// coloured.
+type Theme struct {
+	Key         string
+	String      string
+	Number      string
+	Literal     string
+	Punctuation string
+	Tag         string
+	Attribute   string
+	Comment     string
+}
+
+// Themes are the available colour themes, by name.
+var Themes = map[string]*Theme{
+	// default uses the colours of jq.
+	"default": {
+		Key:         "\033[34;1m",
+		String:      "\033[32m",
+		Number:      "\033[36m",
+		Literal:     "\033[90m",
+		Punctuation: "\033[1m",
+		Tag:         "\033[34;1m",
+		Attribute:   "\033[36m",
+		Comment:     "\033[90m",
+	},
+	// light has darker colours, for terminals with a light background.
+	"light": {
+		Key:       "\033[34m",
+		String:    "\033[32m",
+		Number:    "\033[35m",
+		Literal:   "\033[31m",
+		Tag:       "\033[34m",
+		Attribute: "\033[35m",
+		Comment:   "\033[2m",
+	},
+	"monochrome": {
+		Key:     "\033[1m",
+		Tag:     "\033[1m",
+		Comment: "\033[2m",
+	},
+	"none": {},
+}
+
+// DefaultTheme is the theme used when none is configured.
+const DefaultTheme = "default"
+
+// FindTheme returns the theme with the name, or the default theme if the name
+// is empty.
+func FindTheme(name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	theme, ok := Themes[name]
+	if !ok {
+		names := make([]string, 0, len(Themes))
+		for themeName := range Themes {
+			names = append(names, themeName)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown theme %q, available themes are %s", name, strings.Join(names, ", "))
+	}
+	return theme, nil
+}
+
+// orNone returns the theme, or the theme without colours if it's nil.
+func orNone(theme *Theme) *Theme {
+	if theme == nil {
+		return Themes["none"]
+	}
+	return theme
+}
+
+func (t *Theme) paint(color, text string) string {
+	if color == "" {
+		return text
+	}
+	return color + text + reset
+}
+
+// ColorEnabled reports whether colours can be written to the file: it must be
+// a terminal, and colours must not be disabled with the NO_COLOR environment
+// variable (https://no-color.org).
+func ColorEnabled(file *os.File) bool {
+	return os.Getenv("NO_COLOR") == "" && IsTerminal(file)
+}
+
+// IsTerminal reports whether the file is a terminal.
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Format pretty prints the body according to its content type. Bodies which
+// are not JSON, XML or HTML, or which can't be parsed, are returned as they
+// are. A nil theme disables the colours.
+func Format(body []byte, contentType string, theme *Theme) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if formatted, err := JSON(body, theme); err == nil {
+			return formatted
+		}
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		if formatted, err := XML(body, theme); err == nil {
+			return formatted
+		}
+	case mediaType == "text/html":
+		return HTML(body, theme)
+	default:
+		// Some servers don't send the content type of their JSON.
+		if formatted, err := JSON(body, theme); err == nil {
+			return formatted
+		}
+	}
+	return body
+}
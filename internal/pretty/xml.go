@@ -0,0 +1,100 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// XML indents the XML document. Elements which only contain text are kept on
+// a single line. Text is printed as it was sent, with its entities and CDATA
+// sections.
+func XML(data []byte, theme *Theme) ([]byte, error) {
+	theme = orNone(theme)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var out bytes.Buffer
+	depth := 0
+	// inline reports whether the last token was a start element or its text,
+	// so the end element goes on the same line.
+	inline := false
+	newLine := func() {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(strings.Repeat(indent, depth))
+	}
+
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			newLine()
+			out.WriteString(theme.paint(theme.Tag, "<"+xmlName(token.Name)))
+			for _, attribute := range token.Attr {
+				out.WriteString(" " + theme.paint(theme.Attribute, xmlName(attribute.Name)) + "=")
+				out.WriteString(theme.paint(theme.String, `"`+escapeAttribute(attribute.Value)+`"`))
+			}
+			out.WriteString(theme.paint(theme.Tag, ">"))
+			depth++
+			inline = true
+		case xml.EndElement:
+			depth--
+			if !inline {
+				newLine()
+			}
+			out.WriteString(theme.paint(theme.Tag, "</"+xmlName(token.Name)+">"))
+			inline = false
+		case xml.CharData:
+			// The decoded text has lost its entities and CDATA sections.
+			text := strings.TrimSpace(string(data[start:decoder.InputOffset()]))
+			if text == "" {
+				continue
+			}
+			if !inline {
+				newLine()
+			}
+			out.WriteString(text)
+		case xml.Comment:
+			newLine()
+			out.WriteString(theme.paint(theme.Comment, "<!--"+string(token)+"-->"))
+			inline = false
+		case xml.ProcInst:
+			newLine()
+			out.WriteString(theme.paint(theme.Comment, "<?"+token.Target+" "+string(token.Inst)+"?>"))
+		case xml.Directive:
+			newLine()
+			out.WriteString(theme.paint(theme.Comment, "<!"+string(token)+">"))
+		}
+	}
+
+	if depth != 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return out.Bytes(), nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// escapeAttribute escapes the decoded value of an attribute, only where it's
+// required inside double quotes.
+func escapeAttribute(value string) string {
+	return attributeEscaper.Replace(value)
+}
+
+var attributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
@@ -0,0 +1,50 @@
+package pretty
+
+import "testing"
+
+func TestXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "text is kept as it was sent",
+			input:    "<a><b>It's \"quoted\"\nsecond line</b><![CDATA[x < y]]></a>",
+			expected: "<a>\n  <b>It's \"quoted\"\nsecond line</b>\n  <![CDATA[x < y]]>\n</a>",
+		},
+		{
+			name:     "entities are kept",
+			input:    `<a>x &lt; y &amp; z &#39;</a>`,
+			expected: `<a>x &lt; y &amp; z &#39;</a>`,
+		},
+		{
+			name:     "attributes only escape what's required",
+			input:    `<a title='say "hi" &amp; it&apos;s &lt;b&gt;'/>`,
+			expected: `<a title="say &quot;hi&quot; &amp; it's &lt;b>"></a>`,
+		},
+		{
+			name:     "nested elements are indented",
+			input:    `<?xml version="1.0"?><list><!-- items --><item id="1">one</item><item/></list>`,
+			expected: "<?xml version=\"1.0\"?>\n<list>\n  <!-- items -->\n  <item id=\"1\">one</item>\n  <item></item>\n</list>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := XML([]byte(test.input), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != test.expected {
+				t.Errorf("expected\n%s\ngot\n%s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestXMLUnclosed(t *testing.T) {
+	if _, err := XML([]byte(`<a><b></b>`), nil); err == nil {
+		t.Error("expected an error")
+	}
+}
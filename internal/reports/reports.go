@@ -0,0 +1,260 @@
+package reports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/testrunner"
+	"github.com/joaocgduarte/httpmate/internal/timing"
+)
+
+const (
+	FormatJUnit = "junit"
+	FormatTAP   = "tap"
+)
+
+// Report is a machine-readable output of a test run. An empty path means
+// the report is written to stdout.
+type Report struct {
+	Format string
+	Path   string
+}
+
+// ParseReport parses a "format" or "format=path" report definition.
+func ParseReport(definition string) (Report, error) {
+	format, path, _ := strings.Cut(definition, "=")
+	report := Report{Format: strings.ToLower(strings.TrimSpace(format)), Path: strings.TrimSpace(path)}
+	if report.Path == "-" {
+		report.Path = ""
+	}
+
+	if report.Format != FormatJUnit && report.Format != FormatTAP {
+		return report, fmt.Errorf("unknown report format %q, available formats are %s and %s", format, FormatJUnit, FormatTAP)
+	}
+	return report, nil
+}
+
+// ParseReports parses the report definitions. Only one of the reports can be
+// written to stdout, since they would be mixed.
+func ParseReports(definitions []string) ([]Report, error) {
+	reports := make([]Report, 0, len(definitions))
+	stdout := ""
+	for _, definition := range definitions {
+		report, err := ParseReport(definition)
+		if err != nil {
+			return nil, err
+		}
+		if report.IsStdout() {
+			if stdout != "" {
+				return nil, fmt.Errorf("only one report can be written to stdout, but both %s and %s are, write the others to a file with \"format=path\"", stdout, report.Format)
+			}
+			stdout = report.Format
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// IsStdout reports whether the report is written to stdout.
+func (r Report) IsStdout() bool {
+	return r.Path == ""
+}
+
+// Write writes the report of the test run to its path (or stdout).
+func (r Report) Write(suiteName string, results []*testrunner.CaseResult) error {
+	var w io.Writer = os.Stdout
+	if !r.IsStdout() {
+		file, err := os.Create(r.Path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch r.Format {
+	case FormatJUnit:
+		return WriteJUnit(w, suiteName, results)
+	default:
+		return WriteTAP(w, results)
+	}
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, with one test case per
+// request.
+func WriteJUnit(w io.Writer, suiteName string, results []*testrunner.CaseResult) error {
+	suite := junitTestSuite{
+		Name:      suiteName,
+		Tests:     len(results),
+		Timestamp: time.Now().Format("2006-01-02T15:04:05"),
+		TestCases: make([]junitTestCase, 0, len(results)),
+	}
+
+	var totalTime time.Duration
+	for _, result := range results {
+		totalTime += result.Duration
+		testCase := junitTestCase{
+			Name:      result.Request,
+			ClassName: result.Collection,
+			Time:      formatSeconds(result.Duration),
+		}
+
+		if result.Status != "" {
+			testCase.SystemOut = fmt.Sprintf("HTTP status: %s\nTimings: %s", result.Status, formatTimings(result.Timings))
+		}
+
+		failures := result.Failures()
+		switch {
+		case result.Err != nil:
+			suite.Errors++
+			testCase.Error = &junitFailure{
+				Message: result.Err.Error(),
+				Type:    "RequestError",
+				Content: result.Err.Error(),
+			}
+		case len(failures) > 0:
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: failures[0],
+				Type:    "AssertionError",
+				Content: strings.Join(failures, "\n"),
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = formatSeconds(totalTime)
+
+	report := junitTestSuites{
+		Tests:      suite.Tests,
+		Failures:   suite.Failures,
+		Errors:     suite.Errors,
+		Time:       suite.Time,
+		TestSuites: []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteTAP writes the results in the Test Anything Protocol (version 13), with
+// the details of each request in a YAML block.
+func WriteTAP(w io.Writer, results []*testrunner.CaseResult) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+
+	for i, result := range results {
+		outcome := "ok"
+		if !result.Passed() {
+			outcome = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", outcome, i+1, result.Name())
+
+		b.WriteString("  ---\n")
+		if result.Status != "" {
+			fmt.Fprintf(&b, "  status: %q\n", result.Status)
+		}
+		fmt.Fprintf(&b, "  duration_ms: %s\n", formatMilliseconds(result.Duration))
+		if result.Status != "" {
+			b.WriteString("  timings_ms:\n")
+			for _, phase := range timingPhases(result.Timings) {
+				fmt.Fprintf(&b, "    %s: %s\n", phase.name, formatMilliseconds(phase.duration))
+			}
+		}
+
+		if failures := result.Failures(); len(failures) > 0 {
+			b.WriteString("  failures:\n")
+			for _, failure := range failures {
+				fmt.Fprintf(&b, "    - %q\n", failure)
+			}
+		}
+		b.WriteString("  ...\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type timingPhase struct {
+	name     string
+	duration time.Duration
+}
+
+func timingPhases(timings timing.Timings) []timingPhase {
+	return []timingPhase{
+		{"dns", timings.DNS},
+		{"connect", timings.Connect},
+		{"tls", timings.TLS},
+		{"send", timings.Send},
+		{"wait", timings.Wait},
+		{"transfer", timings.Transfer},
+		{"total", timings.Total},
+	}
+}
+
+// formatTimings formats the phases of a request as "name=milliseconds" pairs.
+func formatTimings(timings timing.Timings) string {
+	phases := timingPhases(timings)
+	pairs := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		pairs = append(pairs, fmt.Sprintf("%s=%sms", phase.name, formatMilliseconds(phase.duration)))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func formatSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+func formatMilliseconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(duration)/float64(time.Millisecond))
+}
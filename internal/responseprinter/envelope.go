@@ -0,0 +1,122 @@
+package responseprinter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joaocgduarte/httpmate/internal/timing"
+)
+
+// envelope is the response, and the request which was sent, as a single JSON
+// document for other tools.
+type envelope struct {
+	Request    envelopeRequest    `json:"request"`
+	Redirects  []envelopeRedirect `json:"redirects,omitempty"`
+	Status     int                `json:"status"`
+	StatusText string             `json:"status_text"`
+	Protocol   string             `json:"protocol"`
+	Headers    http.Header        `json:"headers"`
+	// Body is the body as text or, if it's binary, as base64 (and then
+	// BodyEncoding is "base64").
+	Body         string `json:"body"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+	// JSON is the decoded body, if it's valid JSON.
+	JSON json.RawMessage `json:"json,omitempty"`
+	// SavedTo is the file to which the body was saved, instead of Body.
+	SavedTo string          `json:"saved_to,omitempty"`
+	Timings envelopeTimings `json:"timings"`
+}
+
+type envelopeRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type envelopeRedirect struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	Location string `json:"location"`
+}
+
+// envelopeTimings are the phases of the request, in milliseconds.
+type envelopeTimings struct {
+	DNS              float64 `json:"dns_ms"`
+	Connect          float64 `json:"connect_ms"`
+	TLS              float64 `json:"tls_ms"`
+	Send             float64 `json:"send_ms"`
+	Wait             float64 `json:"wait_ms"`
+	Transfer         float64 `json:"transfer_ms"`
+	Total            float64 `json:"total_ms"`
+	ReusedConnection bool    `json:"reused_connection"`
+}
+
+func newEnvelope(resp *http.Response, body []byte, timings timing.Timings, options Options) envelope {
+	redirects := redirectChain(resp)
+
+	// The request which was sent is the first one, before any redirect.
+	req := resp.Request
+	if len(redirects) > 0 {
+		req = redirects[0].Request
+	}
+
+	result := envelope{
+		Request: envelopeRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header,
+		},
+		Status:     resp.StatusCode,
+		StatusText: strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)+" "),
+		Protocol:   resp.Proto,
+		Headers:    resp.Header,
+		SavedTo:    options.SavedTo,
+		Timings: envelopeTimings{
+			DNS:              milliseconds(timings.DNS),
+			Connect:          milliseconds(timings.Connect),
+			TLS:              milliseconds(timings.TLS),
+			Send:             milliseconds(timings.Send),
+			Wait:             milliseconds(timings.Wait),
+			Transfer:         milliseconds(timings.Transfer),
+			Total:            milliseconds(timings.Total),
+			ReusedConnection: timings.ReusedConnection,
+		},
+	}
+	result.Request.Body, result.Request.BodyEncoding = encodeBody(options.RequestBody)
+	result.Body, result.BodyEncoding = encodeBody(body)
+	if len(body) > 0 && json.Valid(body) {
+		result.JSON = body
+	}
+
+	for _, redirect := range redirects {
+		result.Redirects = append(result.Redirects, envelopeRedirect{
+			Method:   redirect.Request.Method,
+			URL:      redirect.Request.URL.String(),
+			Status:   redirect.StatusCode,
+			Location: redirect.Header.Get("Location"),
+		})
+	}
+
+	return result
+}
+
+// encodeBody returns the body as text or, if it isn't valid UTF-8, as base64
+// with its encoding.
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
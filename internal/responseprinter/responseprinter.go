@@ -1,25 +1,130 @@
 package responseprinter
 
 import (
-	"bytes"
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
-	"time"
+	"sort"
+	"strings"
 
+	"github.com/joaocgduarte/httpmate/internal/download"
+	"github.com/joaocgduarte/httpmate/internal/filter"
+	"github.com/joaocgduarte/httpmate/internal/pretty"
+	"github.com/joaocgduarte/httpmate/internal/timing"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
-func PrintHTTPResponse(resp *http.Response, processingTime time.Duration) {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error reading response body:", err)
+// The outputs are what is printed of the response.
+const (
+	// OutputFull prints the status, headers, body and timings.
+	OutputFull = "full"
+	// OutputBody prints only the body.
+	OutputBody = "body"
+	// OutputHeaders prints only the headers.
+	OutputHeaders = "headers"
+	// OutputStatus prints only the status.
+	OutputStatus = "status"
+	// OutputJSON prints the request and response as a JSON document.
+	OutputJSON = "json"
+)
+
+// Outputs are the available outputs.
+var Outputs = []string{OutputFull, OutputBody, OutputHeaders, OutputStatus, OutputJSON}
+
+// ValidateOutput checks whether the output is one of the available outputs.
+func ValidateOutput(output string) error {
+	for _, valid := range Outputs {
+		if output == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output %q, it must be one of %s", output, strings.Join(Outputs, ", "))
+}
+
+// Options change how responses are printed.
+type Options struct {
+	// Output is what is printed of the response, full by default.
+	Output string
+	// RequestBody is the body which was sent, for the json output.
+	RequestBody []byte
+	// SavedTo is the file to which the body was saved, if it was, in which
+	// case it isn't printed.
+	SavedTo string
+	// Waterfall shows the phases of the request as a waterfall chart.
+	Waterfall bool
+	// Filter, if set, prints only its results over the JSON body.
+	Filter *filter.Filter
+	// RawOutput prints the string results of the filter without quotes.
+	RawOutput bool
+}
+
+// PrintHTTPResponse prints the response and the phases of the request, which
+// are complete once the body is read. Binary bodies are not printed, except
+// when the body alone is printed to a file or a pipe.
+func PrintHTTPResponse(resp *http.Response, recorder *timing.Recorder, options Options) {
+	contentType := resp.Header.Get("Content-Type")
+	reader := bufio.NewReaderSize(resp.Body, download.SniffLength)
+	sniffed, _ := reader.Peek(download.SniffLength)
+	onlyBody := options.Output == OutputBody && options.Filter == nil
+
+	var body []byte
+	// omitted replaces the body when it isn't printed.
+	omitted := ""
+	switch {
+	case options.SavedTo != "":
+		omitted = fmt.Sprintf("(saved to %s)", options.SavedTo)
+	case onlyBody && !pretty.IsTerminal(os.Stdout):
+		// The body is streamed as it is, since it's saved or piped.
+		_, err := io.Copy(os.Stdout, reader)
+		cobra.CheckErr(err)
 		return
+	case (options.Output == OutputFull || onlyBody) && options.Filter == nil && download.IsBinary(contentType, sniffed):
+		size, err := io.Copy(io.Discard, reader)
+		cobra.CheckErr(err)
+		omitted = fmt.Sprintf("(binary body of %s is not printed, save it with --save-body or --save-dir)", download.FormatSize(size))
+	default:
+		var err error
+		body, err = io.ReadAll(reader)
+		if err != nil {
+			fmt.Println("Error reading response body:", err)
+			return
+		}
 	}
+	timings := recorder.Timings()
 
+	if options.Filter != nil {
+		printFiltered(body, options)
+		return
+	}
+
+	switch options.Output {
+	case OutputBody:
+		if omitted != "" && options.SavedTo == "" {
+			fmt.Fprintln(os.Stderr, "The response body is binary, so it is not printed to the terminal. Save it with --save-body or --save-dir, or redirect the output to a file")
+		}
+		if omitted != "" {
+			return
+		}
+		printBody(body, contentType)
+		return
+	case OutputHeaders:
+		printHeaders(resp.Header)
+		return
+	case OutputStatus:
+		fmt.Println(resp.Status)
+		return
+	case OutputJSON:
+		encoded, err := json.Marshal(newEnvelope(resp, body, timings, options))
+		cobra.CheckErr(err)
+		PrintJSON(encoded)
+		return
+	}
+
+	printRedirects(resp)
 	fmt.Println("Status:", resp.Status)
 	fmt.Println("Headers:")
 
@@ -28,25 +133,93 @@ func PrintHTTPResponse(resp *http.Response, processingTime time.Duration) {
 	}
 
 	fmt.Println("Response:")
-	PrintJSON(body)
-	fmt.Println("Time taken:", processingTime)
+	if omitted != "" {
+		fmt.Println(omitted)
+	} else {
+		fmt.Println(string(pretty.Format(body, contentType, theme())))
+	}
+	printTimings(timings, options.Waterfall)
+	fmt.Println("Time taken:", timings.Total)
 }
 
+// printBody prints the body pretty printed, on terminals.
+func printBody(body []byte, contentType string) {
+	fmt.Println(string(pretty.Format(body, contentType, theme())))
+}
+
+// printHeaders prints each header value on its own line, sorted by name.
+func printHeaders(header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Printf("%s: %s\n", name, value)
+		}
+	}
+}
+
+// redirectChain returns the responses of every redirect which was followed
+// to get the response, from the first one.
+func redirectChain(resp *http.Response) []*http.Response {
+	redirects := make([]*http.Response, 0)
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirects = append([]*http.Response{req.Response}, redirects...)
+	}
+	return redirects
+}
+
+// printRedirects prints every redirect which was followed to get the
+// response, from the first one.
+func printRedirects(resp *http.Response) {
+	redirects := redirectChain(resp)
+	if len(redirects) == 0 {
+		return
+	}
+
+	fmt.Println("Redirects:")
+	for _, redirect := range redirects {
+		fmt.Printf("  %s %s -> %s (%s)\n", redirect.Request.Method, redirect.Request.URL, redirect.Header.Get("Location"), redirect.Status)
+	}
+}
+
+// printFiltered prints each result of the filter over the body, so the
+// output can be used in scripts.
+func printFiltered(body []byte, options Options) {
+	results, err := options.Filter.RunJSON(body)
+	for _, result := range results {
+		if text, ok := result.(string); ok && options.RawOutput {
+			fmt.Println(text)
+			continue
+		}
+
+		encoded, encodeErr := json.Marshal(result)
+		cobra.CheckErr(encodeErr)
+		PrintJSON(encoded)
+	}
+	cobra.CheckErr(err)
+}
+
+// PrintJSON pretty prints the JSON document, or prints it as it is if it
+// isn't valid JSON.
 func PrintJSON(toPrint []byte) {
-	if json.Valid(toPrint) && isJQAvailable() {
-		cmd := exec.Command("jq", ".")
-		cmd.Stdin = io.NopCloser(os.Stdin)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Stdin = bytes.NewReader(toPrint)
-		err := cmd.Run()
-		cobra.CheckErr(err)
-	} else {
-		fmt.Println(string(toPrint))
+	formatted, err := pretty.JSON(toPrint, theme())
+	if err != nil {
+		formatted = toPrint
 	}
+	fmt.Println(string(formatted))
 }
 
-func isJQAvailable() bool {
-	err := exec.Command("jq", "--version").Run()
-	return err == nil
+// theme returns the configured colour theme, or nil if colours are disabled.
+func theme() *pretty.Theme {
+	if !pretty.ColorEnabled(os.Stdout) {
+		return nil
+	}
+
+	theme, err := pretty.FindTheme(viper.GetString("theme"))
+	cobra.CheckErr(err)
+	return theme
 }
@@ -0,0 +1,74 @@
+package responseprinter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/timing"
+)
+
+const waterfallWidth = 40
+
+type phase struct {
+	name     string
+	duration time.Duration
+}
+
+// printTimings prints the duration of each phase of the request or, as a
+// waterfall, when each phase started and ended.
+func printTimings(timings timing.Timings, waterfall bool) {
+	phases := []phase{
+		{"DNS lookup", timings.DNS},
+		{"TCP connection", timings.Connect},
+		{"TLS handshake", timings.TLS},
+		{"Request sent", timings.Send},
+		{"Time to first byte", timings.Wait},
+		{"Content transfer", timings.Transfer},
+	}
+
+	fmt.Println("Timings:")
+	if timings.ReusedConnection {
+		fmt.Println("  (reused connection)")
+	}
+
+	var offset time.Duration
+	for _, phase := range phases {
+		duration := "-"
+		if phase.duration > 0 {
+			duration = formatDuration(phase.duration)
+		}
+
+		if !waterfall {
+			fmt.Printf("  %-20s %s\n", phase.name+":", duration)
+			continue
+		}
+
+		fmt.Printf("  %-20s %10s  |%s|\n", phase.name, duration, waterfallBar(offset, phase.duration, timings.Total))
+		offset += phase.duration
+	}
+}
+
+// waterfallBar draws the phase from its start (offset) to its end, over the
+// total time of the request.
+func waterfallBar(offset, duration, total time.Duration) string {
+	if total <= 0 {
+		return strings.Repeat(" ", waterfallWidth)
+	}
+
+	start := min(int(int64(offset)*waterfallWidth/int64(total)), waterfallWidth)
+	length := int(int64(duration) * waterfallWidth / int64(total))
+	if duration > 0 && length == 0 {
+		length = 1
+	}
+	length = min(length, waterfallWidth-start)
+	if length == 0 && start == waterfallWidth && duration > 0 {
+		start, length = waterfallWidth-1, 1
+	}
+
+	return strings.Repeat(" ", start) + strings.Repeat("█", length) + strings.Repeat(" ", waterfallWidth-start-length)
+}
+
+func formatDuration(duration time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(duration)/float64(time.Millisecond))
+}
@@ -0,0 +1,162 @@
+package retry
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultBackoff    = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// DefaultStatusCodes are the status codes which are retried when the policy
+// doesn't list any.
+var DefaultStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Policy defines when a request is sent again. The wait between attempts
+// starts at backoff and doubles after each one, up to max_backoff, unless the
+// server asks for a specific wait with Retry-After.
+type Policy struct {
+	// Attempts is the number of retries after the first attempt.
+	Attempts      int    `json:"attempts" yaml:"attempts"`
+	Backoff       string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	MaxBackoff    string `json:"max_backoff,omitempty" yaml:"maxBackoff,omitempty"`
+	StatusCodes   []int  `json:"status_codes,omitempty" yaml:"statusCodes,omitempty"`
+	NetworkErrors bool   `json:"network_errors,omitempty" yaml:"networkErrors,omitempty"`
+}
+
+// Validate checks that the durations of the policy can be parsed.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.Attempts < 0 {
+		return fmt.Errorf("retry attempts can't be negative")
+	}
+	for name, value := range map[string]string{"backoff": p.Backoff, "max_backoff": p.MaxBackoff} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid retry %s %q, it must be like 500ms or 2s", name, value)
+		}
+	}
+	return nil
+}
+
+// Transport wraps the base transport with the retries of the policy.
+// onRetry, if not nil, is called before waiting for each retry.
+func (p *Policy) Transport(base http.RoundTripper, onRetry func(attempt int, wait time.Duration, reason string)) http.RoundTripper {
+	if p == nil || p.Attempts == 0 {
+		return base
+	}
+	return &transport{base: base, policy: p, onRetry: onRetry}
+}
+
+type transport struct {
+	base    http.RoundTripper
+	policy  *Policy
+	onRetry func(attempt int, wait time.Duration, reason string)
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := parseDuration(t.policy.Backoff, defaultBackoff)
+	maxBackoff := parseDuration(t.policy.MaxBackoff, defaultMaxBackoff)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt > t.policy.Attempts || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		// The body was already sent, so it can only be sent again if it can
+		// be recreated.
+		retry := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			retry.Body = body
+		}
+
+		wait := min(backoff, maxBackoff)
+		backoff = min(backoff*2, maxBackoff)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+			resp.Body.Close()
+		}
+
+		if t.onRetry != nil {
+			t.onRetry(attempt, wait, reason)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = retry
+	}
+}
+
+func (t *transport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// Requests which timed out, or were canceled, are not retried.
+		return t.policy.NetworkErrors && req.Context().Err() == nil
+	}
+
+	statusCodes := t.policy.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultStatusCodes
+	}
+	for _, statusCode := range statusCodes {
+		if resp.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter parses the wait of a Retry-After header, which is either a
+// number of seconds or a date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func parseDuration(value string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || value == "" {
+		return defaultValue
+	}
+	return duration
+}
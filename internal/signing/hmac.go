@@ -0,0 +1,222 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmSHA1   = "sha1"
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+
+	EncodingHex    = "hex"
+	EncodingBase64 = "base64"
+
+	TimestampUnix       = "unix"
+	TimestampUnixMillis = "unix_ms"
+	TimestampRFC3339    = "rfc3339"
+
+	defaultStringToSign = "${method}\n${path}\n${timestamp}\n${body}"
+	defaultHeader       = "X-Signature"
+)
+
+var placeholderRegex = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// HMAC signs requests with an HMAC of a string built from the request. The
+// string to sign is a template with these placeholders:
+//   - ${method}, ${host}, ${path}, ${query} and ${url}
+//   - ${timestamp}, the time of the signature in timestamp_format ("unix",
+//     the default, "unix_ms" or "rfc3339")
+//   - ${body} and ${body_sha256}, the hex encoded SHA-256 of the body
+//   - ${header:Name}, the value of a header of the request
+//
+// The signature is sent in header (X-Signature by default), after prefix,
+// and the timestamp is also sent in timestamp_header, if set.
+type HMAC struct {
+	Secret          string `json:"secret"`
+	Algorithm       string `json:"algorithm,omitempty"`
+	StringToSign    string `json:"string_to_sign,omitempty"`
+	Header          string `json:"header,omitempty"`
+	Prefix          string `json:"prefix,omitempty"`
+	Encoding        string `json:"encoding,omitempty"`
+	TimestampHeader string `json:"timestamp_header,omitempty"`
+	TimestampFormat string `json:"timestamp_format,omitempty"`
+}
+
+// Resolve returns a copy of the signer with its fields resolved, e.g. with
+// their {{variables}} replaced.
+func (h *HMAC) Resolve(resolve func(string) string) *HMAC {
+	if h == nil {
+		return nil
+	}
+
+	return &HMAC{
+		Secret:          resolve(h.Secret),
+		Algorithm:       h.Algorithm,
+		StringToSign:    resolve(h.StringToSign),
+		Header:          resolve(h.Header),
+		Prefix:          resolve(h.Prefix),
+		Encoding:        h.Encoding,
+		TimestampHeader: resolve(h.TimestampHeader),
+		TimestampFormat: h.TimestampFormat,
+	}
+}
+
+// Validate checks that the signer has a secret and known options.
+func (h *HMAC) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	if h.Secret == "" {
+		return fmt.Errorf("hmac signature requires a secret")
+	}
+	if _, err := h.newHash(); err != nil {
+		return err
+	}
+	switch h.Encoding {
+	case "", EncodingHex, EncodingBase64:
+	default:
+		return fmt.Errorf("hmac encoding must be %q or %q, got %q", EncodingHex, EncodingBase64, h.Encoding)
+	}
+	switch h.TimestampFormat {
+	case "", TimestampUnix, TimestampUnixMillis, TimestampRFC3339:
+	default:
+		return fmt.Errorf("unknown hmac timestamp_format %q", h.TimestampFormat)
+	}
+	return nil
+}
+
+// HeaderName returns the header in which the signature is sent.
+func (h *HMAC) HeaderName() string {
+	if h.Header == "" {
+		return defaultHeader
+	}
+	return h.Header
+}
+
+// Sign adds the signature (and the timestamp) headers to the request. It must
+// be the last change to the request, so the signature covers exactly what is
+// sent.
+func (h *HMAC) Sign(req *http.Request, now time.Time) error {
+	if h == nil {
+		return nil
+	}
+
+	newHash, err := h.newHash()
+	if err != nil {
+		return err
+	}
+	body, err := ReadBody(req)
+	if err != nil {
+		return err
+	}
+
+	timestamp := h.formatTimestamp(now)
+	if h.TimestampHeader != "" {
+		req.Header.Set(h.TimestampHeader, timestamp)
+	}
+
+	stringToSign, err := h.stringToSign(req, body, timestamp)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(newHash, []byte(h.Secret))
+	mac.Write([]byte(stringToSign))
+	sum := mac.Sum(nil)
+	signature := hex.EncodeToString(sum)
+	if h.Encoding == EncodingBase64 {
+		signature = base64.StdEncoding.EncodeToString(sum)
+	}
+
+	req.Header.Set(h.HeaderName(), h.Prefix+signature)
+	return nil
+}
+
+// stringToSign replaces the placeholders of the template with the values of
+// the request.
+func (h *HMAC) stringToSign(req *http.Request, body []byte, timestamp string) (string, error) {
+	template := h.StringToSign
+	if template == "" {
+		template = defaultStringToSign
+	}
+
+	var unknown []string
+	stringToSign := placeholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := placeholderValue(name, req, body, timestamp)
+		if !ok {
+			unknown = append(unknown, placeholder)
+		}
+		return value
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown hmac placeholders: %s", strings.Join(unknown, ", "))
+	}
+	return stringToSign, nil
+}
+
+func placeholderValue(name string, req *http.Request, body []byte, timestamp string) (string, bool) {
+	if header, found := strings.CutPrefix(name, "header:"); found {
+		return req.Header.Get(header), true
+	}
+
+	switch name {
+	case "method":
+		return req.Method, true
+	case "host":
+		if req.Host != "" {
+			return req.Host, true
+		}
+		return req.URL.Host, true
+	case "path":
+		return req.URL.EscapedPath(), true
+	case "query":
+		return req.URL.RawQuery, true
+	case "url":
+		return req.URL.String(), true
+	case "timestamp":
+		return timestamp, true
+	case "body":
+		return string(body), true
+	case "body_sha256":
+		hash := sha256.Sum256(body)
+		return hex.EncodeToString(hash[:]), true
+	}
+	return "", false
+}
+
+func (h *HMAC) newHash() (func() hash.Hash, error) {
+	switch h.Algorithm {
+	case "", AlgorithmSHA256:
+		return sha256.New, nil
+	case AlgorithmSHA1:
+		return sha1.New, nil
+	case AlgorithmSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unknown hmac algorithm %q", h.Algorithm)
+}
+
+func (h *HMAC) formatTimestamp(now time.Time) string {
+	switch h.TimestampFormat {
+	case TimestampUnixMillis:
+		return strconv.FormatInt(now.UnixMilli(), 10)
+	case TimestampRFC3339:
+		return now.UTC().Format(time.RFC3339)
+	}
+	return strconv.FormatInt(now.Unix(), 10)
+}
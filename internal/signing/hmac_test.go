@@ -0,0 +1,211 @@
+package signing
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+func newRequest(t *testing.T) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/orders?page=2&sort=id", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestStringToSign(t *testing.T) {
+	tests := []struct {
+		name     string
+		hmac     HMAC
+		expected string
+	}{
+		{
+			name:     "default",
+			hmac:     HMAC{},
+			expected: "POST\n/v1/orders\n1704164645\n{\"id\":1}",
+		},
+		{
+			name:     "request placeholders",
+			hmac:     HMAC{StringToSign: "${method} ${host} ${path} ${query} ${url}"},
+			expected: "POST api.example.com /v1/orders page=2&sort=id https://api.example.com/v1/orders?page=2&sort=id",
+		},
+		{
+			name:     "body hash",
+			hmac:     HMAC{StringToSign: "${body_sha256}"},
+			expected: "037c9214eef74cc3887f3a4f085b4e17d76280dafd273b0ee160c09c4ba1cfd4",
+		},
+		{
+			name:     "headers",
+			hmac:     HMAC{StringToSign: "${header:content-type}|${header:X-Missing}"},
+			expected: "application/json|",
+		},
+		{
+			name:     "unix millis timestamp",
+			hmac:     HMAC{StringToSign: "${timestamp}", TimestampFormat: TimestampUnixMillis},
+			expected: "1704164645006",
+		},
+		{
+			name:     "rfc3339 timestamp",
+			hmac:     HMAC{StringToSign: "${timestamp}", TimestampFormat: TimestampRFC3339},
+			expected: "2024-01-02T03:04:05Z",
+		},
+		{
+			name:     "text around placeholders",
+			hmac:     HMAC{StringToSign: "v1:${method}:{not a placeholder}:$method"},
+			expected: "v1:POST:{not a placeholder}:$method",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := newRequest(t)
+			stringToSign, err := test.hmac.stringToSign(req, []byte(`{"id":1}`), test.hmac.formatTimestamp(now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stringToSign != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, stringToSign)
+			}
+		})
+	}
+}
+
+func TestStringToSignUnknownPlaceholders(t *testing.T) {
+	h := &HMAC{Secret: "secret", StringToSign: "${method}\n${nonce}\n${bdy}"}
+
+	err := h.Sign(newRequest(t), now)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "unknown hmac placeholders: ${nonce}, ${bdy}"
+	if err.Error() != expected {
+		t.Errorf("expected the error %q, got %q", expected, err)
+	}
+}
+
+// The signatures without placeholders are the known answers of RFC 2202 and
+// RFC 4231.
+func TestSign(t *testing.T) {
+	rfcTest := "what do ya want for nothing?"
+
+	tests := []struct {
+		name     string
+		hmac     HMAC
+		header   string
+		expected string
+	}{
+		{
+			name:     "sha256",
+			hmac:     HMAC{Secret: "Jefe", StringToSign: rfcTest},
+			header:   "X-Signature",
+			expected: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		{
+			name:     "sha1",
+			hmac:     HMAC{Secret: "Jefe", StringToSign: rfcTest, Algorithm: AlgorithmSHA1},
+			header:   "X-Signature",
+			expected: "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79",
+		},
+		{
+			name:     "sha512",
+			hmac:     HMAC{Secret: "Jefe", StringToSign: rfcTest, Algorithm: AlgorithmSHA512},
+			header:   "X-Signature",
+			expected: "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+		},
+		{
+			name:     "base64 with a prefix and header",
+			hmac:     HMAC{Secret: "Jefe", StringToSign: rfcTest, Encoding: EncodingBase64, Header: "Authorization", Prefix: "HMAC "},
+			header:   "Authorization",
+			expected: "HMAC W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=",
+		},
+		{
+			name:     "default string to sign",
+			hmac:     HMAC{Secret: "secret"},
+			header:   "X-Signature",
+			expected: "fb48020ce813f7bb1c02d4fb7e96125d6ba9b7317eb6f3ae2e8c467641938f9c",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := newRequest(t)
+			if err := test.hmac.Sign(req, now); err != nil {
+				t.Fatal(err)
+			}
+			if signature := req.Header.Get(test.header); signature != test.expected {
+				t.Errorf("expected the signature %q, got %q", test.expected, signature)
+			}
+		})
+	}
+}
+
+func TestSignTimestampHeader(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "", expected: "1704164645"},
+		{format: TimestampUnix, expected: "1704164645"},
+		{format: TimestampUnixMillis, expected: "1704164645006"},
+		{format: TimestampRFC3339, expected: "2024-01-02T03:04:05Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			h := &HMAC{Secret: "secret", TimestampHeader: "X-Timestamp", TimestampFormat: test.format}
+			req := newRequest(t)
+			// The timestamp is in UTC, whatever the zone of the clock.
+			if err := h.Sign(req, now.In(time.FixedZone("WET", 3600))); err != nil {
+				t.Fatal(err)
+			}
+			if timestamp := req.Header.Get("X-Timestamp"); timestamp != test.expected {
+				t.Errorf("expected the timestamp %q, got %q", test.expected, timestamp)
+			}
+		})
+	}
+}
+
+func TestSignKeepsTheBody(t *testing.T) {
+	req := newRequest(t)
+	if err := (&HMAC{Secret: "secret"}).Sign(req, now); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"id":1}` {
+		t.Errorf("expected the body to still be sent, got %q", body)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		hmac     HMAC
+		expected string
+	}{
+		{hmac: HMAC{}, expected: "hmac signature requires a secret"},
+		{hmac: HMAC{Secret: "s", Algorithm: "md5"}, expected: `unknown hmac algorithm "md5"`},
+		{hmac: HMAC{Secret: "s", Encoding: "base32"}, expected: `hmac encoding must be "hex" or "base64", got "base32"`},
+		{hmac: HMAC{Secret: "s", TimestampFormat: "iso"}, expected: `unknown hmac timestamp_format "iso"`},
+	}
+
+	for _, test := range tests {
+		err := test.hmac.Validate()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected the error %q, got %v", test.expected, err)
+		}
+	}
+	if err := (&HMAC{Secret: "s", Algorithm: AlgorithmSHA512, Encoding: EncodingBase64}).Validate(); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+}
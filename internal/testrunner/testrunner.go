@@ -0,0 +1,119 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/joaocgduarte/httpmate/internal/assertions"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+	"github.com/joaocgduarte/httpmate/internal/timing"
+)
+
+type CaseResult struct {
+	Collection string
+	Request    string
+	Status     string
+	StatusCode int
+	Duration   time.Duration
+	Timings    timing.Timings
+	Assertions []assertions.Result
+	Err        error
+}
+
+// Name identifies the test case as "collection/request".
+func (c *CaseResult) Name() string {
+	return fmt.Sprintf("%s/%s", c.Collection, c.Request)
+}
+
+func (c *CaseResult) Passed() bool {
+	return c.Err == nil && len(c.Failures()) == 0
+}
+
+// Failures returns the messages of the failed assertions, or the error that
+// prevented the request from being performed.
+func (c *CaseResult) Failures() []string {
+	failures := make([]string, 0)
+	if c.Err != nil {
+		return append(failures, c.Err.Error())
+	}
+
+	for _, result := range c.Assertions {
+		if !result.Passed {
+			failures = append(failures, result.Message)
+		}
+	}
+	return failures
+}
+
+// CountFailed returns how many cases failed.
+func CountFailed(results []*CaseResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ExitCode returns the exit code of a test run, which is non-zero if any case
+// failed.
+func ExitCode(results []*CaseResult) int {
+	if CountFailed(results) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// NewErrorResult is the result of a request which could not be performed,
+// e.g. because of invalid settings.
+func NewErrorResult(reqConfig *configs.RequestConfig, err error) *CaseResult {
+	return &CaseResult{
+		Collection: filepath.Base(reqConfig.Collection),
+		Request:    reqConfig.RequestName,
+		Err:        err,
+	}
+}
+
+// Run performs the request and evaluates its assertions. Requests without
+// assertions pass as long as a response is received. Requests which can't be
+// built fail with the error, without stopping the run.
+func Run(client *http.Client, reqConfig *configs.RequestConfig) *CaseResult {
+	result := &CaseResult{
+		Collection: filepath.Base(reqConfig.Collection),
+		Request:    reqConfig.RequestName,
+	}
+
+	httpReq, err := reqConfig.HTTPRequest()
+	if err != nil {
+		result.Err = fmt.Errorf("invalid request: %w", err)
+		return result
+	}
+	req, recorder := timing.Trace(httpReq)
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	recorder.WrapBody(resp)
+
+	result.Status = resp.Status
+	result.StatusCode = resp.StatusCode
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Err = fmt.Errorf("error reading response body: %w", err)
+		return result
+	}
+	result.Timings = recorder.Timings()
+
+	result.Assertions = reqConfig.Assertions.Evaluate(resp, body, result.Duration)
+	return result
+}
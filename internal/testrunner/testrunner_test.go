@@ -0,0 +1,170 @@
+package testrunner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joaocgduarte/httpmate/internal/assertions"
+	"github.com/joaocgduarte/httpmate/internal/configs"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "name": "httpmate"}`)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newRequest(t *testing.T, server *httptest.Server, path string, a *assertions.Assertions) *configs.RequestConfig {
+	t.Helper()
+
+	return &configs.RequestConfig{
+		Collection:  filepath.Join(t.TempDir(), "users"),
+		RequestName: strings.Trim(path, "/"),
+		Domain:      server.URL,
+		Path:        path,
+		Method:      http.MethodGet,
+		Assertions:  a,
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func TestRunPasses(t *testing.T) {
+	server := newServer(t)
+	reqConfig := newRequest(t, server, "/users/1", &assertions.Assertions{
+		Status:    intPointer(http.StatusOK),
+		Headers:   []assertions.HeaderAssertion{{Name: "Content-Type", Contains: stringPointer("json")}},
+		JSONPath:  []assertions.JSONPathAssertion{{Path: "$.name", Equals: []byte(`"httpmate"`)}},
+		BodyRegex: []string{`"id":\s*1`},
+	})
+
+	result := Run(server.Client(), reqConfig)
+
+	if !result.Passed() {
+		t.Fatalf("expected the case to pass, got %v", result.Failures())
+	}
+	if result.Name() != "users/users/1" {
+		t.Errorf("unexpected name %q", result.Name())
+	}
+	if result.StatusCode != http.StatusOK || result.Status != "200 OK" {
+		t.Errorf("unexpected status %q", result.Status)
+	}
+	if len(result.Assertions) != 4 {
+		t.Errorf("expected 4 assertion results, got %d", len(result.Assertions))
+	}
+	if result.Timings.Total <= 0 {
+		t.Error("expected the timings to be recorded")
+	}
+	if ExitCode([]*CaseResult{result}) != 0 {
+		t.Error("expected a zero exit code")
+	}
+}
+
+func TestRunWithoutAssertionsPasses(t *testing.T) {
+	server := newServer(t)
+	reqConfig := newRequest(t, server, "/echo", nil)
+
+	result := Run(server.Client(), reqConfig)
+
+	if !result.Passed() {
+		t.Fatalf("expected the case to pass, got %v", result.Failures())
+	}
+	if result.StatusCode != http.StatusCreated {
+		t.Errorf("unexpected status %d", result.StatusCode)
+	}
+}
+
+func TestRunFails(t *testing.T) {
+	server := newServer(t)
+	reqConfig := newRequest(t, server, "/users/1", &assertions.Assertions{
+		Status:   intPointer(http.StatusNotFound),
+		JSONPath: []assertions.JSONPathAssertion{{Path: "$.name"}},
+	})
+
+	result := Run(server.Client(), reqConfig)
+
+	if result.Passed() {
+		t.Fatal("expected the case to fail")
+	}
+	failures := result.Failures()
+	if len(failures) != 1 || !strings.Contains(failures[0], "expected status 404, got 200") {
+		t.Errorf("unexpected failures %v", failures)
+	}
+}
+
+func TestRunFailsWithoutResponse(t *testing.T) {
+	server := newServer(t)
+	reqConfig := newRequest(t, server, "/users/1", nil)
+	server.Close()
+
+	result := Run(server.Client(), reqConfig)
+
+	if result.Passed() || result.Err == nil {
+		t.Fatal("expected the case to fail with an error")
+	}
+	if len(result.Failures()) != 1 {
+		t.Errorf("expected the error as the only failure, got %v", result.Failures())
+	}
+}
+
+func TestRunFailsWithInvalidRequest(t *testing.T) {
+	server := newServer(t)
+	missing := filepath.Join(t.TempDir(), "missing.bin")
+	invalid := newRequest(t, server, "/echo", nil)
+	invalid.Method = http.MethodPost
+	invalid.Body.BinaryFileBody = &missing
+	valid := newRequest(t, server, "/users/1", nil)
+
+	results := []*CaseResult{
+		Run(server.Client(), invalid),
+		Run(server.Client(), valid),
+	}
+
+	if results[0].Passed() || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "invalid request") {
+		t.Errorf("expected the invalid request to fail, got %v", results[0].Err)
+	}
+	if !results[1].Passed() {
+		t.Errorf("expected the next request to still run and pass, got %v", results[1].Failures())
+	}
+	if CountFailed(results) != 1 {
+		t.Errorf("expected 1 failed case, got %d", CountFailed(results))
+	}
+	if ExitCode(results) == 0 {
+		t.Error("expected a non-zero exit code")
+	}
+}
+
+func TestNewErrorResult(t *testing.T) {
+	reqConfig := &configs.RequestConfig{Collection: "/collections/users", RequestName: "get"}
+	result := NewErrorResult(reqConfig, errors.New("invalid timeout"))
+
+	if result.Passed() || result.Name() != "users/get" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if ExitCode([]*CaseResult{result}) != 1 {
+		t.Error("expected a non-zero exit code")
+	}
+}
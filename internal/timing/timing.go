@@ -0,0 +1,162 @@
+package timing
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings are the phases of a request. The phases which didn't happen (e.g.
+// DNS lookup and connection, when a connection was reused) are zero.
+type Timings struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	Send     time.Duration
+	Wait     time.Duration
+	Transfer time.Duration
+	Total    time.Duration
+	// ReusedConnection reports whether the request was sent through a
+	// connection of a previous request (e.g. of a redirect).
+	ReusedConnection bool
+}
+
+// Recorder records the phases of a request, through an httptrace of the
+// request and the reading of the response body. With redirects and retries,
+// only the phases of the last request are kept, since they're the ones of
+// the response which is shown.
+type Recorder struct {
+	mu       sync.Mutex
+	start    time.Time
+	bodyDone time.Time
+	phases   phases
+}
+
+type phases struct {
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// Trace returns the request instrumented to record its phases. The recording
+// starts when Trace is called.
+func Trace(req *http.Request) (*http.Request, *Recorder) {
+	recorder := &Recorder{start: time.Now()}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			recorder.record(func() {
+				// A new request (e.g. a redirect) starts, so the phases of
+				// the previous one are discarded.
+				recorder.phases = phases{}
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { recorder.now(&recorder.phases.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { recorder.now(&recorder.phases.dnsDone) },
+		ConnectStart: func(string, string) {
+			recorder.record(func() {
+				// Only the first attempt of the connection is kept.
+				if recorder.phases.connectStart.IsZero() {
+					recorder.phases.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone:       func(string, string, error) { recorder.now(&recorder.phases.connectDone) },
+		TLSHandshakeStart: func() { recorder.now(&recorder.phases.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { recorder.now(&recorder.phases.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			recorder.record(func() {
+				recorder.phases.gotConn = time.Now()
+				recorder.phases.reused = info.Reused
+			})
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { recorder.now(&recorder.phases.wroteRequest) },
+		GotFirstResponseByte: func() { recorder.now(&recorder.phases.firstByte) },
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), recorder
+}
+
+func (r *Recorder) record(update func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update()
+}
+
+func (r *Recorder) now(field *time.Time) {
+	r.record(func() {
+		*field = time.Now()
+	})
+}
+
+// WrapBody makes the recorder measure the transfer of the response body,
+// which ends when the body is read completely or closed.
+func (r *Recorder) WrapBody(resp *http.Response) {
+	resp.Body = &bodyRecorder{ReadCloser: resp.Body, recorder: r}
+}
+
+// Timings returns the phases recorded so far. If the body wasn't read yet,
+// the transfer is measured until now.
+func (r *Recorder) Timings() Timings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	end := r.bodyDone
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	return Timings{
+		DNS:              between(r.phases.dnsStart, r.phases.dnsDone),
+		Connect:          between(r.phases.connectStart, r.phases.connectDone),
+		TLS:              between(r.phases.tlsStart, r.phases.tlsDone),
+		Send:             between(r.phases.gotConn, r.phases.wroteRequest),
+		Wait:             between(r.phases.wroteRequest, r.phases.firstByte),
+		Transfer:         between(r.phases.firstByte, end),
+		Total:            end.Sub(r.start),
+		ReusedConnection: r.phases.reused,
+	}
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+type bodyRecorder struct {
+	io.ReadCloser
+	recorder *Recorder
+}
+
+func (b *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *bodyRecorder) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
+
+func (b *bodyRecorder) done() {
+	b.recorder.record(func() {
+		if b.recorder.bodyDone.IsZero() {
+			b.recorder.bodyDone = time.Now()
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joaocgduarte/httpmate/internal/files"
+	"github.com/spf13/cobra"
+)
+
+const (
+	environmentsDirectoryName = ".environments"
+	storeFileName             = ".variables.json"
+)
+
+var placeholderRegex = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// Interpolate replaces every {{name}} placeholder in value with the matching
+// variable. Placeholders without a matching variable are kept untouched.
+func Interpolate(value string, variables map[string]string) string {
+	if len(variables) == 0 {
+		return value
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		if replacement, ok := variables[name]; ok {
+			return replacement
+		}
+		return placeholder
+	})
+}
+
+// EnvironmentsDirectory returns the directory in which the environment files
+// of a collection (or of the collections directory itself) are stored.
+func EnvironmentsDirectory(directory string) string {
+	return filepath.Join(directory, environmentsDirectoryName)
+}
+
+// LoadEnvironment reads the environment with the given name. The variables
+// defined at the collections directory level are shared by every collection,
+// and the ones defined inside a collection directory override them for that
+// collection and its sub-collections.
+func LoadEnvironment(collectionsPath, collectionPath, name string) map[string]string {
+	result := map[string]string{}
+	if name == "" {
+		return result
+	}
+
+	found := false
+	for _, directory := range CollectionDirectories(collectionsPath, collectionPath) {
+		environmentPath := filepath.Join(EnvironmentsDirectory(directory), fmt.Sprintf("%s.json", name))
+		environment, err := readEnvironmentFile(environmentPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		cobra.CheckErr(err)
+
+		found = true
+		for key, value := range environment {
+			result[key] = value
+		}
+	}
+
+	if !found {
+		cobra.CheckErr(fmt.Errorf("environment %q does not exist", name))
+	}
+
+	return result
+}
+
+// SaveEnvironment writes the variables of an environment to the given
+// collection (or collections) directory, keeping the variables it already had.
+func SaveEnvironment(directory, name string, vars map[string]string) {
+	files.CreateDirectory(EnvironmentsDirectory(directory))
+	environmentPath := filepath.Join(EnvironmentsDirectory(directory), fmt.Sprintf("%s.json", name))
+
+	environment, err := readEnvironmentFile(environmentPath)
+	if os.IsNotExist(err) {
+		environment = map[string]string{}
+	} else {
+		cobra.CheckErr(err)
+	}
+
+	for key, value := range vars {
+		environment[key] = value
+	}
+	files.WriteStructToJSONFile(environment, environmentPath)
+}
+
+// LoadStore reads the variables extracted from previous responses, which are
+// shared by every collection.
+func LoadStore(collectionsPath string) map[string]string {
+	store, err := readEnvironmentFile(filepath.Join(collectionsPath, storeFileName))
+	if os.IsNotExist(err) {
+		return map[string]string{}
+	}
+	cobra.CheckErr(err)
+	return store
+}
+
+// SaveToStore adds variables to the store, replacing the previous values of
+// the same variables.
+func SaveToStore(collectionsPath string, vars map[string]string) {
+	store := LoadStore(collectionsPath)
+	for key, value := range vars {
+		store[key] = value
+	}
+	files.WriteStructToJSONFile(store, filepath.Join(collectionsPath, storeFileName))
+}
+
+// CollectionDirectories returns the directories whose environments (and
+// settings) apply to a collection, from the outermost (the collections
+// directory) to the collection itself.
+func CollectionDirectories(collectionsPath, collectionPath string) []string {
+	if collectionPath == "" {
+		return []string{collectionsPath}
+	}
+
+	relativePath, err := filepath.Rel(collectionsPath, collectionPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return []string{collectionsPath, collectionPath}
+	}
+
+	directories := []string{collectionsPath}
+	current := collectionsPath
+	for _, part := range strings.Split(relativePath, string(filepath.Separator)) {
+		if part == "." || part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		directories = append(directories, current)
+	}
+	return directories
+}
+
+func readEnvironmentFile(environmentPath string) (map[string]string, error) {
+	byteValue, err := os.ReadFile(environmentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var environment map[string]string
+	if err := json.Unmarshal(byteValue, &environment); err != nil {
+		return nil, fmt.Errorf("invalid environment file %s: %w", environmentPath, err)
+	}
+	return environment, nil
+}